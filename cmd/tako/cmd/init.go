@@ -70,6 +70,16 @@ func init() {
 
 	flags.BoolP("skaffold", "s", false, "prepare the project for Skaffold")
 
+	flags.Bool("skaffold-kube-contexts", false, "bind Skaffold environment profiles to <env>-context kube-contexts")
+
+	flags.String("skaffold-manifests-format", "kubernetes", "format of manifests deployed by Skaffold environment profiles (kubernetes|kustomize|helm)")
+
+	flags.String("skaffold-tag-policy", "gitTagger", "policy used by Skaffold to tag built images (gitTagger|sha256|dateTime|envTemplate)")
+
+	flags.Bool("skaffold-status-check", true, "make Skaffold environment profiles wait for deployed resources to stabilize")
+
+	flags.Int("skaffold-status-check-deadline", tako.DefaultStatusCheckDeadlineSeconds, "seconds Skaffold environment profiles wait for deployed resources to stabilize")
+
 	rootCmd.AddCommand(initCmd)
 }
 
@@ -77,8 +87,18 @@ func runInitCmd(cmd *cobra.Command, _ []string) error {
 	files, _ := cmd.Flags().GetStringSlice("file")
 	envs, _ := cmd.Flags().GetStringSlice("environment")
 	skaffold, _ := cmd.Flags().GetBool("skaffold")
+	skaffoldKubeContexts, _ := cmd.Flags().GetBool("skaffold-kube-contexts")
+	skaffoldManifestsFormat, _ := cmd.Flags().GetString("skaffold-manifests-format")
+	skaffoldStatusCheck, _ := cmd.Flags().GetBool("skaffold-status-check")
+	skaffoldStatusCheckDeadline, _ := cmd.Flags().GetInt("skaffold-status-check-deadline")
 	verbose, _ := cmd.Root().Flags().GetBool("verbose")
 
+	// tag policy of an existing skaffold config is only replaced when requested explicitly
+	skaffoldTagPolicy := ""
+	if cmd.Flags().Changed("skaffold-tag-policy") {
+		skaffoldTagPolicy, _ = cmd.Flags().GetString("skaffold-tag-policy")
+	}
+
 	// The working directory is always the current directory.
 	// This ensures created manifest yaml entries are portable between users and require no path fixing.
 	wd := "."
@@ -87,6 +107,10 @@ func runInitCmd(cmd *cobra.Command, _ []string) error {
 		tako.WithComposeSources(files),
 		tako.WithEnvs(envs),
 		tako.WithSkaffold(skaffold),
+		tako.WithSkaffoldKubeContexts(skaffoldKubeContexts),
+		tako.WithSkaffoldManifestsFormat(skaffoldManifestsFormat),
+		tako.WithSkaffoldTagPolicy(skaffoldTagPolicy),
+		tako.WithSkaffoldStatusCheck(skaffoldStatusCheck, skaffoldStatusCheckDeadline),
 		tako.WithLogVerbose(verbose),
 	)
 }
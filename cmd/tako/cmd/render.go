@@ -77,6 +77,18 @@ func init() {
 		"Additional Kubernetes manifests to be included in the output",
 	)
 
+	flags.String(
+		"image-tags",
+		"",
+		"YAML file mapping environments to service image tags, e.g. {dev: {web: 1.2.0}}",
+	)
+
+	flags.Bool(
+		"legacy-secret-keys",
+		false,
+		"Store and mount single file secrets under the secret name instead of the secret file base name",
+	)
+
 	rootCmd.AddCommand(renderCmd)
 }
 
@@ -87,6 +99,8 @@ func runRenderCmd(cmd *cobra.Command, _ []string) error {
 	envs, _ := cmd.Flags().GetStringSlice("environment")
 	verbose, _ := cmd.Root().Flags().GetBool("verbose")
 	additionalManifests, _ := cmd.Flags().GetStringSlice("additional-manifests")
+	imageTagsFile, _ := cmd.Flags().GetString("image-tags")
+	legacySecretKeys, _ := cmd.Flags().GetBool("legacy-secret-keys")
 
 	// The working directory is always the current directory.
 	// This ensures created manifest yaml entries are portable between users and require no path fixing.
@@ -97,6 +111,8 @@ func runRenderCmd(cmd *cobra.Command, _ []string) error {
 		tako.WithManifestFormat(format),
 		tako.WithManifestsAsSingleFile(singleFile),
 		tako.WithAdditionalManifests(additionalManifests),
+		tako.WithImageTagsFile(imageTagsFile),
+		tako.WithLegacySecretKeys(legacySecretKeys),
 		tako.WithOutputDir(dir),
 		tako.WithEnvs(envs),
 		tako.WithLogVerbose(verbose),
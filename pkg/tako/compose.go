@@ -20,13 +20,18 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
+	kmd "github.com/appvia/komando"
+	"github.com/appvia/tako/pkg/tako/converter/kubernetes"
 	"github.com/appvia/tako/pkg/tako/log"
 	"github.com/compose-spec/compose-go/cli"
+	"github.com/compose-spec/compose-go/loader"
 	composego "github.com/compose-spec/compose-go/types"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // defaultComposeFileNames defines the Compose file names for auto-discovery (in order of preference)
@@ -68,6 +73,48 @@ func NewComposeProject(paths []string, opts ...ComposeOpts) (*ComposeProject, er
 	return p, nil
 }
 
+// NewComposeProjectFromBytes loads and parses in-memory compose file content and returns a ComposeProject object.
+// The name identifies the content, e.g. `docker-compose.yaml`, while relative paths are resolved against
+// the current working directory. The project is named after the working directory, as for compose files.
+func NewComposeProjectFromBytes(name string, data []byte, opts ...ComposeOpts) (*ComposeProject, error) {
+	raw, err := rawProjectFromBytes(name, data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "when loading %s", name)
+	}
+	version, err := composeVersion(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ComposeProject{version: version, Project: raw}
+	for _, opt := range opts {
+		_, err := opt(p)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// ConvertComposeFiles converts an ordered list of compose files, followed by environment overlay files, to K8s objects.
+// Files are merged with compose override rules, so values in later files win over values in earlier ones.
+func ConvertComposeFiles(files []string, overlays []string, opt kubernetes.ConvertOptions) ([]runtime.Object, error) {
+	paths := append(append([]string{}, files...), overlays...)
+	if len(paths) == 0 {
+		return nil, errors.New("no compose files to convert")
+	}
+
+	p, err := NewComposeProject(paths, WithTransforms)
+	if err != nil {
+		return nil, err
+	}
+
+	opt.InputFiles = paths
+	k := &kubernetes.Kubernetes{Opt: opt, Project: p.Project, UI: kmd.NoOpUI()}
+
+	return k.Transform()
+}
+
 // WithTransforms ensures project attributes are augmented beyond the base compose-go values
 func WithTransforms(p *ComposeProject) (*ComposeProject, error) {
 	return p.transform()
@@ -101,18 +148,69 @@ func rawProjectFromSources(paths []string) (*composego.Project, error) {
 	return cli.ProjectFromOptions(projectOptions)
 }
 
+// rawProjectFromBytes loads and parses a compose-go project from in-memory docker-compose content.
+func rawProjectFromBytes(name string, data []byte) (*composego.Project, error) {
+	config, err := loader.ParseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	return loader.Load(composego.ConfigDetails{
+		WorkingDir:  workingDir,
+		ConfigFiles: []composego.ConfigFile{{Filename: name, Config: config}},
+		Environment: osEnvironment(),
+	}, func(o *loader.Options) {
+		o.Name = composeProjectName(workingDir)
+	})
+}
+
+// projectNameInvalidCharsRegex matches characters compose strips from project names
+var projectNameInvalidCharsRegex = regexp.MustCompile(`[^a-z0-9\\-_]+`)
+
+// composeProjectName returns the project name as compose CLI derives it when loading compose files,
+// i.e. from COMPOSE_PROJECT_NAME environment variable or the working directory name
+func composeProjectName(workingDir string) string {
+	if name, ok := os.LookupEnv(cli.ComposeProjectName); ok {
+		return name
+	}
+
+	return projectNameInvalidCharsRegex.ReplaceAllString(strings.ToLower(filepath.Base(workingDir)), "")
+}
+
+// osEnvironment returns the OS environment variables as a map
+func osEnvironment() map[string]string {
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 {
+			env[parts[0]] = parts[1]
+		}
+	}
+	return env
+}
+
 // getComposeVersion extracts version from compose file and returns a string
 func getComposeVersion(file string) (string, error) {
-	version := struct {
-		Version string `json:"version"` // This affects YAML as well
-	}{}
-
 	compose, err := ioutil.ReadFile(file)
 	if err != nil {
 		return "", err
 	}
 
-	if err = yaml.Unmarshal(compose, &version); err != nil {
+	return composeVersion(compose)
+}
+
+// composeVersion extracts version from compose file content and returns a string
+func composeVersion(compose []byte) (string, error) {
+	version := struct {
+		Version string `json:"version"` // This affects YAML as well
+	}{}
+
+	if err := yaml.Unmarshal(compose, &version); err != nil {
 		return "", err
 	}
 	return version.Version, nil
@@ -0,0 +1,129 @@
+/**
+ * Copyright 2020 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tako_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	kmd "github.com/appvia/komando"
+	"github.com/appvia/tako/pkg/tako"
+	"github.com/appvia/tako/pkg/tako/converter/kubernetes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Compose", func() {
+	Describe("NewComposeProjectFromBytes", func() {
+		compose := []byte(`
+version: "3.7"
+services:
+  web:
+    image: nginx:1.21
+    ports:
+      - 8080:80
+`)
+
+		It("loads the in-memory compose content", func() {
+			p, err := tako.NewComposeProjectFromBytes("docker-compose.yaml", compose)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.GetVersion()).To(Equal("3.7"))
+
+			svc, err := p.GetService("web")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(svc.Image).To(Equal("nginx:1.21"))
+		})
+
+		It("converts the in-memory compose content to K8s objects", func() {
+			p, err := tako.NewComposeProjectFromBytes("docker-compose.yaml", compose, tako.WithTransforms)
+			Expect(err).NotTo(HaveOccurred())
+
+			k := &kubernetes.Kubernetes{Project: p.Project, UI: kmd.NoOpUI()}
+			objects, err := k.Transform()
+			Expect(err).NotTo(HaveOccurred())
+
+			var deployment *v1apps.Deployment
+			for _, o := range objects {
+				if d, ok := o.(*v1apps.Deployment); ok {
+					deployment = d
+				}
+			}
+			Expect(deployment).NotTo(BeNil())
+			Expect(deployment.Name).To(Equal("web"))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.21"))
+		})
+
+		It("returns an error for invalid compose content", func() {
+			_, err := tako.NewComposeProjectFromBytes("docker-compose.yaml", []byte("services: ["))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("names the project after the working directory as for compose files", func() {
+			wd, err := NewTempWorkingDir("init-default/compose-yml/compose.yml")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(wd)
+
+			cwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Chdir(wd)).To(Succeed())
+			defer func() { Expect(os.Chdir(cwd)).To(Succeed()) }()
+
+			fromFile, err := tako.NewComposeProject([]string{filepath.Join(wd, "compose.yml")})
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := ioutil.ReadFile(filepath.Join(wd, "compose.yml"))
+			Expect(err).NotTo(HaveOccurred())
+			fromBytes, err := tako.NewComposeProjectFromBytes("compose.yml", data)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fromBytes.Name).To(Equal("composeyml"))
+			Expect(fromBytes.Name).To(Equal(fromFile.Name))
+		})
+	})
+
+	Describe("ConvertComposeFiles", func() {
+		workingDir := "testdata/convert-override"
+
+		It("converts compose files merged with override semantics", func() {
+			objects, err := tako.ConvertComposeFiles(
+				[]string{workingDir + "/docker-compose.yaml", workingDir + "/docker-compose.override.yaml"},
+				[]string{workingDir + "/docker-compose.env.dev.yaml"},
+				kubernetes.ConvertOptions{},
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			var deployment *v1apps.Deployment
+			for _, o := range objects {
+				if d, ok := o.(*v1apps.Deployment); ok {
+					deployment = d
+				}
+			}
+			Expect(deployment).NotTo(BeNil())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("nginx:1.21"))
+			Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(3))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(ContainElement(v1.EnvVar{Name: "LOG_LEVEL", Value: "debug"}))
+		})
+
+		It("returns an error without compose files", func() {
+			_, err := tako.ConvertComposeFiles(nil, nil, kubernetes.ConvertOptions{})
+			Expect(err).To(MatchError("no compose files to convert"))
+		})
+	})
+})
@@ -41,9 +41,6 @@ const (
 	// DefaultImagePullSecret default image pull credentials secret name
 	DefaultImagePullSecret = ""
 
-	// JobWorkload workload type
-	JobWorkload = "Job"
-
 	// DefaultReplicaNumber default number of replicas per workload
 	DefaultReplicaNumber = 1
 
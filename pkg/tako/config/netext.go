@@ -0,0 +1,95 @@
+/**
+ * Copyright 2020 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+
+	composego "github.com/compose-spec/compose-go/types"
+	"github.com/go-playground/validator/v10"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// NetworkExtension represents the root of the docker-compose extensions for a network
+type NetworkExtension struct {
+	K8S NetK8sConfig `yaml:"x-k8s"`
+}
+
+// NetK8sConfig represents the root of the k8s specific fields supported by tako for a network.
+type NetK8sConfig struct {
+	Egress NetworkEgress `yaml:"egress,omitempty"`
+}
+
+// NetworkEgress holds egress rules of the network policy generated for a network.
+// When enabled, pods attached to the network may only reach other pods on the same network,
+// cluster DNS and the explicitly allowed destinations.
+type NetworkEgress struct {
+	Enabled bool         `yaml:"enabled,omitempty"`
+	Allow   []EgressRule `yaml:"allow,omitempty" validate:"dive"`
+}
+
+// EgressRule allows egress traffic to an IP block, optionally restricted to the given TCP ports
+type EgressRule struct {
+	CIDR  string `yaml:"cidr" validate:"required,cidr"`
+	Ports []int  `yaml:"ports,omitempty" validate:"dive,gte=1,lte=65535"`
+}
+
+// Validate validates a network's K8s config
+func (nkc NetK8sConfig) Validate() error {
+	if err := validator.New().Struct(nkc); err != nil {
+		validationErrors := err.(validator.ValidationErrors)
+		for _, e := range validationErrors {
+			switch e.Tag() {
+			case "required":
+				return fmt.Errorf("%s is required", e.StructNamespace())
+			case "cidr":
+				return fmt.Errorf("%s is invalid, use CIDR notation, e.g. 10.0.0.0/16", e.StructNamespace())
+			case "gte", "lte":
+				return fmt.Errorf("%s is invalid, use a port number between 1 and 65535", e.StructNamespace())
+			}
+		}
+		return errors.New(validationErrors[0].Error())
+	}
+
+	return nil
+}
+
+// NetK8sConfigFromCompose returns a NetK8sConfig from a compose-go NetworkConfig
+func NetK8sConfigFromCompose(network *composego.NetworkConfig) (NetK8sConfig, error) {
+	if _, ok := network.Extensions[K8SExtensionKey]; !ok {
+		return NetK8sConfig{}, nil
+	}
+
+	var ext NetworkExtension
+
+	var buf bytes.Buffer
+	if err := yaml.NewEncoder(&buf).Encode(network.Extensions); err != nil {
+		return NetK8sConfig{}, err
+	}
+
+	if err := yaml.NewDecoder(&buf).Decode(&ext); err != nil {
+		return NetK8sConfig{}, err
+	}
+
+	if err := ext.K8S.Validate(); err != nil {
+		return NetK8sConfig{}, err
+	}
+
+	return ext.K8S, nil
+}
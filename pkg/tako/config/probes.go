@@ -111,17 +111,19 @@ type ProbeConfig struct {
 	TCP  TCPProbe  `yaml:"tcp,omitempty"`
 	Exec ExecProbe `yaml:"exec,omitempty"`
 
-	InitialDelay     time.Duration `yaml:"initialDelay,omitempty"`
-	Period           time.Duration `yaml:"period,omitempty"`
-	FailureThreshold int           `yaml:"failureThreshold,omitempty"`
-	SuccessThreshold int           `yaml:"successThreshold,omitempty"`
-	Timeout          time.Duration `yaml:"timeout,omitempty"`
+	InitialDelay     time.Duration `yaml:"initialDelay,omitempty" validate:"gte=0"`
+	Period           time.Duration `yaml:"period,omitempty" validate:"gte=0"`
+	FailureThreshold int           `yaml:"failureThreshold,omitempty" validate:"gte=0"`
+	SuccessThreshold int           `yaml:"successThreshold,omitempty" validate:"gte=0"`
+	Timeout          time.Duration `yaml:"timeout,omitempty" validate:"gte=0"`
 }
 
 // HTTPProbe holds the necessary properties to define the http check on the k8s probe.
 type HTTPProbe struct {
-	Port int    `yaml:"port"`
-	Path string `yaml:"path"`
+	Port    int               `yaml:"port"`
+	Path    string            `yaml:"path"`
+	Scheme  string            `yaml:"scheme,omitempty" validate:"oneof='' HTTP HTTPS"`
+	Headers map[string]string `yaml:"headers,omitempty"`
 }
 
 // TCPProbe holds the necessary properties to define the tcp check on the k8s probe.
@@ -18,9 +18,10 @@ package config
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,9 +36,15 @@ import (
 const (
 	K8SExtensionKey         = "x-k8s"
 	dnsSubdomainNamePattern = `^[a-zA-Z]([a-zA-Z0-9\-]+[\.]?)*[a-zA-Z0-9]$`
+	intOrPercentPattern     = `^[0-9]+%?$`
+	cpuPattern              = `^([0-9]+(\.[0-9]+)?|\.[0-9]+|[0-9]+m)$`
 )
 
-var dnsSubdomainNameRegex = regexp.MustCompile(dnsSubdomainNamePattern)
+var (
+	dnsSubdomainNameRegex = regexp.MustCompile(dnsSubdomainNamePattern)
+	intOrPercentRegex     = regexp.MustCompile(intOrPercentPattern)
+	cpuRegex              = regexp.MustCompile(cpuPattern)
+)
 
 // ServiceExtension represents the root of the docker-compose extensions for a service
 type ServiceExtension struct {
@@ -69,7 +76,8 @@ func (skc SvcK8sConfig) Map() (map[string]interface{}, error) {
 func (skc SvcK8sConfig) Merge(other SvcK8sConfig) (SvcK8sConfig, error) {
 	k8s := skc
 
-	if err := mergo.Merge(&k8s, other, mergo.WithOverride); err != nil {
+	// pointers aren't dereferenced so that explicitly set zero values, e.g. 0 or false, override base values
+	if err := mergo.Merge(&k8s, other, mergo.WithOverride, mergo.WithoutDereference); err != nil {
 		return SvcK8sConfig{}, err
 	}
 
@@ -95,21 +103,92 @@ func (skc SvcK8sConfig) Validate() error {
 		return err
 	}
 
+	if err := validate.RegisterValidation("intOrPercent", validateIntOrPercent); err != nil {
+		return err
+	}
+
+	if err := validate.RegisterValidation("cpu", validateCPU); err != nil {
+		return err
+	}
+
+	if err := validate.RegisterValidation("quantity", validateQuantity); err != nil {
+		return err
+	}
+
+	problems := []string{}
+
 	err := validate.Struct(skc)
 	if err != nil {
+		// @step report every invalid field at once rather than failing on the first one
 		validationErrors := err.(validator.ValidationErrors)
 		for _, e := range validationErrors {
-			if e.Tag() == "required" {
-				return fmt.Errorf("%s is required", e.StructNamespace())
-			}
+			problems = append(problems, svcK8sConfigFieldError(e))
 		}
+	}
 
-		return errors.New(validationErrors[0].Error())
+	// @step k8s rejects DaemonSet rolling updates which can't make any pod unavailable
+	if maxUnavailable := skc.Workload.RollingUpdateMaxUnavailable; maxUnavailable != "" && WorkloadTypesEqual(skc.Workload.Type, DaemonSetWorkload) {
+		if n, err := strconv.Atoi(strings.TrimSuffix(maxUnavailable, "%")); err == nil && n == 0 {
+			problems = append(problems, fmt.Sprintf(
+				"SvcK8sConfig.Workload.RollingUpdateMaxUnavailable must be greater than 0 for DaemonSets, got %q",
+				maxUnavailable,
+			))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid %s configuration:\n  - %s", K8SExtensionKey, strings.Join(problems, "\n  - "))
 	}
 
 	return nil
 }
 
+// svcK8sConfigFieldError describes a single field validation error, listing allowed values where known
+func svcK8sConfigFieldError(e validator.FieldError) string {
+	field := e.StructNamespace()
+
+	var allowed []string
+	switch e.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "gte":
+		return fmt.Sprintf("%s must be greater than or equal to %s, got %v", field, e.Param(), e.Value())
+	case "subdomainIfAny":
+		return fmt.Sprintf("%s must be a valid DNS subdomain name, got %q", field, e.Value())
+	case "intOrPercent":
+		return fmt.Sprintf("%s must be a non negative integer or percentage, got %q", field, e.Value())
+	case "cpu":
+		return fmt.Sprintf("%s must be a number of CPUs, e.g. 0.5, or millicpu, e.g. 500m, got %q", field, e.Value())
+	case "quantity":
+		return fmt.Sprintf("%s must be a resource quantity, e.g. 500M or 1Gi, got %q", field, e.Value())
+	case "oneof":
+		allowed = strings.Fields(strings.ReplaceAll(e.Param(), "''", "\"\""))
+	case "workloadType":
+		allowed = allowedValues(workloadTypes)
+	case "restartPolicy":
+		allowed = allowedValues(restartPolicies)
+	case "serviceType":
+		allowed = allowedValues(serviceTypes)
+	default:
+		return fmt.Sprintf("%s is invalid (failed on the '%s' rule)", field, e.Tag())
+	}
+
+	return fmt.Sprintf("%s has invalid value %q, allowed values: %s", field, e.Value(), strings.Join(allowed, ", "))
+}
+
+// allowedValues returns sorted names of valid enum values
+func allowedValues[T ~string](values map[T]bool) []string {
+	names := []string{}
+	for v, ok := range values {
+		if ok {
+			names = append(names, string(v))
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
 // DefaultSvcK8sConfig returns a service's K8S Config with set defaults.
 func DefaultSvcK8sConfig() SvcK8sConfig {
 	return SvcK8sConfig{
@@ -183,6 +262,26 @@ func SvcK8sConfigFromCompose(svc *composego.ServiceConfig) (SvcK8sConfig, error)
 	return cfg, nil
 }
 
+// healthcheckCommand converts a compose healthcheck test into an exec probe command.
+// `CMD` tests are executed directly while `CMD-SHELL` tests are run by a shell, same as docker does.
+func healthcheckCommand(test []string) []string {
+	if len(test) == 0 {
+		return test
+	}
+
+	switch strings.ToUpper(test[0]) {
+	case "CMD":
+		return test[1:]
+	case "CMD-SHELL":
+		if len(test) == 1 {
+			return test[1:]
+		}
+		return []string{"sh", "-c", strings.Join(test[1:], " ")}
+	default:
+		return test
+	}
+}
+
 func WorkloadRollingUpdateMaxSurgeFromCompose(svc *composego.ServiceConfig) int {
 	if svc.Deploy == nil || svc.Deploy.UpdateConfig == nil {
 		return DefaultRollingUpdateMaxSurge
@@ -206,6 +305,29 @@ func ResourceFromCompose(svc *composego.ServiceConfig) (Resource, error) {
 		cpuRequest = svc.Deploy.Resources.Reservations.NanoCPUs
 	}
 
+	// @step fall back to legacy top level resource keys when not set in the deploy block
+	if memLimit == "" && svc.MemLimit > 0 {
+		memLimit = getMemoryQuantity(int64(svc.MemLimit))
+	}
+
+	if cpuLimit == "" && svc.CPUS > 0 {
+		cpuLimit = strconv.FormatFloat(float64(svc.CPUS), 'f', -1, 32)
+	}
+
+	if memRequest == "" && svc.MemReservation > 0 {
+		memRequest = getMemoryQuantity(int64(svc.MemReservation))
+	}
+
+	// cpu_shares are relative weights where 1024 shares is the equivalent of a single CPU,
+	// 1m is the smallest CPU request K8s accepts
+	if cpuRequest == "" && svc.CPUShares > 0 {
+		millis := svc.CPUShares * 1000 / 1024
+		if millis < 1 {
+			millis = 1
+		}
+		cpuRequest = fmt.Sprintf("%dm", millis)
+	}
+
 	return Resource{
 		MaxMemory: memLimit,
 		Memory:    memRequest,
@@ -272,10 +394,19 @@ func ServiceTypeFromCompose(svc *composego.ServiceConfig) (ServiceType, error) {
 
 	if len(svc.Ports) > 0 {
 		candidate = "clusterip"
-	}
-
-	if svc.Deploy != nil && svc.Deploy.EndpointMode == "vip" {
-		candidate = "nodeport"
+	} else if len(svc.Expose) > 0 {
+		// ports exposed only to linked services are reachable directly on the pods
+		candidate = "headless"
+	}
+
+	if svc.Deploy != nil {
+		switch svc.Deploy.EndpointMode {
+		case "vip":
+			candidate = "nodeport"
+		case "dnsrr":
+			// DNS round-robin resolves the service name directly to its task addresses
+			candidate = "headless"
+		}
 	}
 
 	serviceType, err := inferServiceTypeFromComposeValue(candidate)
@@ -315,8 +446,13 @@ func WorkloadReplicasFromCompose(svc *composego.ServiceConfig) int {
 }
 
 func WorkloadTypeFromCompose(svc *composego.ServiceConfig) WorkloadType {
-	if svc.Deploy != nil && svc.Deploy.Mode == "global" {
-		return DaemonSetWorkload
+	if svc.Deploy != nil {
+		switch svc.Deploy.Mode {
+		case "global":
+			return DaemonSetWorkload
+		case "replicated-job", "global-job":
+			return JobWorkload
+		}
 	}
 
 	if len(svc.Volumes) != 0 {
@@ -334,18 +470,17 @@ func LivenessProbeFromCompose(svc *composego.ServiceConfig) LivenessProbe {
 		return DefaultLivenessProbe()
 	}
 
-	if healthcheck.Disable {
+	test := healthcheck.Test
+
+	// compose `test: ["NONE"]` disables the healthcheck, same as `disable: true`
+	if healthcheck.Disable || (len(test) > 0 && strings.ToUpper(test[0]) == "NONE") {
 		res.Type = ProbeTypeNone.String()
 		return res
 	}
 
 	res.Type = ProbeTypeExec.String()
 
-	test := healthcheck.Test
-	if len(test) > 0 && (strings.ToLower(test[0]) == "cmd" || strings.ToLower(test[0]) == "cmd-shell") {
-		test = test[1:]
-	}
-	res.Exec.Command = test
+	res.Exec.Command = healthcheckCommand(test)
 
 	if healthcheck.Timeout != nil {
 		res.Timeout = time.Duration(*healthcheck.Timeout)
@@ -413,31 +548,66 @@ func validateDNSSubdomainNameIfAny(fl validator.FieldLevel) bool {
 	return dnsSubdomainNameRegex.MatchString(target) && len(target) <= 253
 }
 
+// validateIntOrPercent validates a non negative integer or percentage value, e.g. 2 or 25%
+func validateIntOrPercent(fl validator.FieldLevel) bool {
+	return intOrPercentRegex.MatchString(fl.Field().String())
+}
+
+// IsCPUQuantity checks whether a CPU quantity is given as a number of CPUs, e.g. 0.5, or in millicpu, e.g. 500m.
+// Other quantity suffixes, e.g. 1Gi, aren't meaningful for CPU and are rejected.
+func IsCPUQuantity(s string) bool {
+	return cpuRegex.MatchString(strings.TrimSpace(s))
+}
+
+// validateCPU validates a CPU quantity, see IsCPUQuantity
+func validateCPU(fl validator.FieldLevel) bool {
+	return IsCPUQuantity(fl.Field().String())
+}
+
+// validateQuantity validates a non negative resource quantity, e.g. 500M or 1Gi
+func validateQuantity(fl validator.FieldLevel) bool {
+	q, err := resource.ParseQuantity(strings.TrimSpace(fl.Field().String()))
+	return err == nil && q.Sign() >= 0
+}
+
 // Workload holds all the workload-related k8s configurations.
 type Workload struct {
-	Type                  WorkloadType      `yaml:"type,omitempty" validate:"workloadType"`
-	Replicas              int               `yaml:"replicas" validate:""`
-	ServiceAccountName    string            `yaml:"serviceAccountName,omitempty" validate:"subdomainIfAny"`
-	RollingUpdateMaxSurge int               `yaml:"rollingUpdateMaxSurge,omitempty" validate:""`
-	Annotations           map[string]string `yaml:"annotations,omitempty"`
-	LivenessProbe         LivenessProbe     `yaml:"livenessProbe,omitempty"`
-	ReadinessProbe        ReadinessProbe    `yaml:"readinessProbe,omitempty"`
-	RestartPolicy         RestartPolicy     `yaml:"restartPolicy,omitempty" validate:"restartPolicy"`
-	ImagePull             ImagePull         `yaml:"imagePull,omitempty"`
-	Resource              Resource          `yaml:"resource,omitempty"`
-	Autoscale             Autoscale         `yaml:"autoscale,omitempty"`
-	PodSecurity           PodSecurity       `yaml:"podSecurity,omitempty"`
-	Command               []string          `yaml:"command,omitempty"`
-	CommandArgs           []string          `yaml:"commandArgs,omitempty"`
+	Type                          WorkloadType      `yaml:"type,omitempty" validate:"workloadType"`
+	Replicas                      int               `yaml:"replicas" validate:""`
+	ServiceAccountName            string            `yaml:"serviceAccountName,omitempty" validate:"subdomainIfAny"`
+	ServiceAccount                ServiceAccount    `yaml:"serviceAccount,omitempty"`
+	RollingUpdateMaxSurge         int               `yaml:"rollingUpdateMaxSurge,omitempty" validate:""`
+	UpdateStrategy                string            `yaml:"updateStrategy,omitempty" validate:"oneof='' Recreate RollingUpdate OnDelete"`
+	RollingUpdateMaxUnavailable   string            `yaml:"rollingUpdateMaxUnavailable,omitempty" validate:"omitempty,intOrPercent"`
+	Annotations                   map[string]string `yaml:"annotations,omitempty"`
+	PodAnnotations                map[string]string `yaml:"podAnnotations,omitempty"`
+	WorkloadAnnotations           map[string]string `yaml:"workloadAnnotations,omitempty"`
+	LivenessProbe                 LivenessProbe     `yaml:"livenessProbe,omitempty"`
+	ReadinessProbe                ReadinessProbe    `yaml:"readinessProbe,omitempty"`
+	RestartPolicy                 RestartPolicy     `yaml:"restartPolicy,omitempty" validate:"restartPolicy"`
+	ImagePull                     ImagePull         `yaml:"imagePull,omitempty"`
+	Resource                      Resource          `yaml:"resource,omitempty"`
+	Autoscale                     Autoscale         `yaml:"autoscale,omitempty"`
+	PodSecurity                   PodSecurity       `yaml:"podSecurity,omitempty"`
+	Command                       []string          `yaml:"command,omitempty"`
+	CommandArgs                   []string          `yaml:"commandArgs,omitempty"`
+	TerminationGracePeriodSeconds *int64            `yaml:"terminationGracePeriodSeconds,omitempty" validate:"omitempty,gte=0"`
+	AutomountServiceAccountToken  *bool             `yaml:"automountServiceAccountToken,omitempty"`
+	HostUsers                     *bool             `yaml:"hostUsers,omitempty"`
+	RBAC                          RBAC              `yaml:"rbac,omitempty"`
+	StatefulSet                   StatefulSet       `yaml:"statefulSet,omitempty"`
+	Job                           Job               `yaml:"job,omitempty"`
+	BoundTokens                   []BoundToken      `yaml:"boundTokens,omitempty" validate:"dive"`
+	EnvFrom                       []EnvFrom         `yaml:"envFrom,omitempty" validate:"dive"`
 }
 
 type Resource struct {
-	Memory     string `yaml:"memory,omitempty"`
-	MaxMemory  string `yaml:"maxMemory,omitempty"`
-	CPU        string `yaml:"cpu,omitempty"`
-	MaxCPU     string `yaml:"maxCpu,omitempty"`
-	Storage    string `yaml:"storage,omitempty"`
-	MaxStorage string `yaml:"maxStorage,omitempty"`
+	Memory     string `yaml:"memory,omitempty" validate:"omitempty,quantity"`
+	MaxMemory  string `yaml:"maxMemory,omitempty" validate:"omitempty,quantity"`
+	CPU        string `yaml:"cpu,omitempty" validate:"omitempty,cpu"`
+	MaxCPU     string `yaml:"maxCpu,omitempty" validate:"omitempty,cpu"`
+	Storage    string `yaml:"storage,omitempty" validate:"omitempty,quantity"`
+	MaxStorage string `yaml:"maxStorage,omitempty" validate:"omitempty,quantity"`
 }
 
 type ImagePull struct {
@@ -457,16 +627,76 @@ type PodSecurity struct {
 	FsGroup    *int64 `yaml:"fsGroup,omitempty"`
 }
 
+// StatefulSet holds StatefulSet workload specific configuration
+type StatefulSet struct {
+	PVCRetentionPolicy PVCRetentionPolicy `yaml:"pvcRetentionPolicy,omitempty"`
+}
+
+// Job holds Job workload specific configuration
+type Job struct {
+	Completions    *int   `yaml:"completions,omitempty" validate:"omitempty,gte=1"`
+	Parallelism    *int   `yaml:"parallelism,omitempty" validate:"omitempty,gte=0"`
+	CompletionMode string `yaml:"completionMode,omitempty" validate:"oneof='' NonIndexed Indexed"`
+}
+
+// PVCRetentionPolicy describes the lifecycle of PVCs created from StatefulSet volume claim templates
+type PVCRetentionPolicy struct {
+	WhenDeleted string `yaml:"whenDeleted,omitempty" validate:"oneof='' Retain Delete"`
+	WhenScaled  string `yaml:"whenScaled,omitempty" validate:"oneof='' Retain Delete"`
+}
+
+// BoundToken describes a Service Account token projected into the workload pod
+type BoundToken struct {
+	Audience          string `yaml:"audience,omitempty"`
+	ExpirationSeconds int64  `yaml:"expirationSeconds,omitempty" validate:"omitempty,gte=600"`
+	MountPath         string `yaml:"mountPath" validate:"required"`
+}
+
+// ServiceAccount holds the workload's Service Account settings
+type ServiceAccount struct {
+	// Create tells whether the Service Account should be created, defaults to true
+	Create *bool `yaml:"create,omitempty"`
+}
+
+// EnvFrom references a ConfigMap or a Secret with all its keys imported as the workload environment variables
+type EnvFrom struct {
+	ConfigMap string `yaml:"configMap,omitempty" validate:"required_without=Secret,excluded_with=Secret"`
+	Secret    string `yaml:"secret,omitempty" validate:"required_without=ConfigMap"`
+	Prefix    string `yaml:"prefix,omitempty"`
+}
+
+// RBAC holds the access rules granted to the workload's Service Account
+type RBAC struct {
+	Rules []RBACRule `yaml:"rules,omitempty" validate:"dive"`
+}
+
+// RBACRule describes a set of actions allowed on a set of resources
+type RBACRule struct {
+	APIGroups []string `yaml:"apiGroups,omitempty"`
+	Resources []string `yaml:"resources" validate:"required"`
+	Verbs     []string `yaml:"verbs" validate:"required"`
+}
+
 // Service will hold the service specific extensions in the future.
 type Service struct {
-	Type     ServiceType `yaml:"type" validate:"serviceType"`
-	NodePort int         `yaml:"nodeport,omitempty"`
-	Expose   Expose      `yaml:"expose,omitempty"`
+	Type          ServiceType       `yaml:"type" validate:"serviceType"`
+	NodePort      int               `yaml:"nodeport,omitempty"`
+	Expose        Expose            `yaml:"expose,omitempty"`
+	NetworkPolicy NetworkPolicy     `yaml:"networkPolicy,omitempty"`
+	Annotations   map[string]string `yaml:"annotations,omitempty"`
+}
+
+// NetworkPolicy holds the service's Network Policy settings
+type NetworkPolicy struct {
+	// Enabled tells whether Network Policies should restrict the service traffic, defaults to true
+	Enabled *bool `yaml:"enabled,omitempty"`
 }
 
 type Expose struct {
-	DomainPrefix       string            `yaml:"domainPrefix,omitempty"`
-	Domain             string            `yaml:"domain,omitempty"`
-	TlsSecret          string            `yaml:"tlsSecret,omitempty"`
-	IngressAnnotations map[string]string `yaml:"ingressAnnotations,omitempty"`
+	DomainPrefix           string                       `yaml:"domainPrefix,omitempty"`
+	Domain                 string                       `yaml:"domain,omitempty"`
+	TlsSecret              string                       `yaml:"tlsSecret,omitempty"`
+	IngressAnnotations     map[string]string            `yaml:"ingressAnnotations,omitempty"`
+	IngressHostAnnotations map[string]map[string]string `yaml:"ingressHostAnnotations,omitempty"`
+	IngressName            string                       `yaml:"ingressName,omitempty" validate:"subdomainIfAny"`
 }
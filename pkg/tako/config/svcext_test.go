@@ -18,6 +18,8 @@ package config_test
 
 import (
 	"bytes"
+	"fmt"
+	"time"
 
 	"github.com/appvia/tako/pkg/tako/config"
 	composego "github.com/compose-spec/compose-go/types"
@@ -237,6 +239,239 @@ var _ = Describe("Service Extension", func() {
 						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Workload.Type"))
 					})
 				})
+
+				Context("with an invalid PVC retention policy", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.StatefulSet.PVCRetentionPolicy.WhenScaled = "Remove"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Workload.StatefulSet.PVCRetentionPolicy.WhenScaled"))
+					})
+				})
+
+				Context("with an invalid rolling update max unavailable", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.RollingUpdateMaxUnavailable = "-1"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError(ContainSubstring(`SvcK8sConfig.Workload.RollingUpdateMaxUnavailable must be a non negative integer or percentage, got "-1"`)))
+					})
+
+					It("returns error for a DaemonSet which can't make any pod unavailable", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.Type = config.DaemonSetWorkload
+
+						for _, v := range []string{"0", "0%"} {
+							svcK8sConfig.Workload.RollingUpdateMaxUnavailable = v
+
+							err = svcK8sConfig.Validate()
+							Expect(err).To(MatchError(ContainSubstring(
+								fmt.Sprintf(`SvcK8sConfig.Workload.RollingUpdateMaxUnavailable must be greater than 0 for DaemonSets, got %q`, v))))
+						}
+					})
+				})
+
+				Context("with an invalid CPU quantity", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.Resource.MaxCPU = "abc"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError(ContainSubstring(`SvcK8sConfig.Workload.Resource.MaxCPU must be a number of CPUs, e.g. 0.5, or millicpu, e.g. 500m, got "abc"`)))
+					})
+
+					It("returns error for a memory style quantity suffix", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.Resource.CPU = "1Gi"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError(ContainSubstring(`SvcK8sConfig.Workload.Resource.CPU must be a number of CPUs, e.g. 0.5, or millicpu, e.g. 500m, got "1Gi"`)))
+					})
+				})
+
+				Context("with an invalid ephemeral storage quantity", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.Resource.MaxStorage = "lots"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError(ContainSubstring(`SvcK8sConfig.Workload.Resource.MaxStorage must be a resource quantity, e.g. 500M or 1Gi, got "lots"`)))
+					})
+				})
+
+				Context("with fractional and millicpu CPU quantities", func() {
+					It("validates successfully", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.Resource.CPU = "0.5"
+						svcK8sConfig.Workload.Resource.MaxCPU = "500m"
+
+						Expect(svcK8sConfig.Validate()).To(Succeed())
+					})
+				})
+
+				Context("with an invalid http probe scheme", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.LivenessProbe.HTTP.Scheme = "FTP"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError(ContainSubstring(`SvcK8sConfig.Workload.LivenessProbe.ProbeConfig.HTTP.Scheme has invalid value "FTP", allowed values: "", HTTP, HTTPS`)))
+					})
+				})
+
+				Context("with a liveness probe success threshold other than 1", func() {
+					It("validates successfully, as the threshold is coerced to 1 on conversion", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.LivenessProbe.SuccessThreshold = 3
+
+						Expect(svcK8sConfig.Validate()).To(Succeed())
+					})
+				})
+
+				Context("with a custom readiness probe success threshold", func() {
+					It("validates successfully", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.ReadinessProbe.SuccessThreshold = 3
+
+						Expect(svcK8sConfig.Validate()).To(Succeed())
+					})
+				})
+
+				Context("with negative probe thresholds and timings", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.ReadinessProbe.FailureThreshold = -1
+						svcK8sConfig.Workload.LivenessProbe.Period = -time.Second
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError(ContainSubstring("SvcK8sConfig.Workload.ReadinessProbe.ProbeConfig.FailureThreshold must be greater than or equal to 0, got -1")))
+						Expect(err).To(MatchError(ContainSubstring("SvcK8sConfig.Workload.LivenessProbe.ProbeConfig.Period must be greater than or equal to 0")))
+					})
+				})
+
+				Context("with an invalid job completion mode", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.Job.CompletionMode = "Ordered"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(MatchError(ContainSubstring(`SvcK8sConfig.Workload.Job.CompletionMode has invalid value "Ordered", allowed values: "", NonIndexed, Indexed`)))
+					})
+				})
+
+				Context("with a bound token expiring in less than 10 minutes", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.BoundTokens = []config.BoundToken{
+							{MountPath: "/var/run/secrets/tokens", ExpirationSeconds: 300},
+						}
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("ExpirationSeconds"))
+					})
+				})
+
+				Context("with a negative termination grace period", func() {
+					It("returns error", func() {
+						gracePeriod := int64(-1)
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.TerminationGracePeriodSeconds = &gracePeriod
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Workload.TerminationGracePeriodSeconds"))
+					})
+				})
+
+				Context("with envFrom referencing both a ConfigMap and a Secret", func() {
+					It("returns error", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.EnvFrom = []config.EnvFrom{{ConfigMap: "settings", Secret: "credentials"}}
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("SvcK8sConfig.Workload.EnvFrom[0].ConfigMap"))
+					})
+				})
+
+				Context("with multiple invalid fields", func() {
+					It("reports all of them along with allowed values", func() {
+						svcK8sConfig := config.DefaultSvcK8sConfig()
+						svcK8sConfig.Workload.Type = "CronJob"
+						svcK8sConfig.Workload.RestartPolicy = "Sometimes"
+						svcK8sConfig.Workload.ImagePull.Policy = "Maybe"
+						svcK8sConfig.Service.Type = "Public"
+
+						err = svcK8sConfig.Validate()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring(`SvcK8sConfig.Workload.Type has invalid value "CronJob", allowed values: DaemonSet, Deployment, Job, StatefulSet`))
+						Expect(err.Error()).To(ContainSubstring(`SvcK8sConfig.Workload.RestartPolicy has invalid value "Sometimes", allowed values: Always, Never, OnFailure`))
+						Expect(err.Error()).To(ContainSubstring(`SvcK8sConfig.Workload.ImagePull.Policy has invalid value "Maybe", allowed values: "", IfNotPresent, Never, Always`))
+						Expect(err.Error()).To(ContainSubstring(`SvcK8sConfig.Service.Type has invalid value "Public", allowed values: ClusterIP, Headless, LoadBalancer, NodePort, None`))
+					})
+				})
+			})
+		})
+	})
+
+	Describe("service type", func() {
+		AfterEach(func() {
+			svc.Ports = nil
+			svc.Expose = nil
+			svc.Deploy = nil
+		})
+
+		Context("for a service which only exposes ports", func() {
+			BeforeEach(func() {
+				svc.Expose = composego.StringOrNumberList{"8080"}
+			})
+
+			It("defaults to a headless service", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(parsedK8sCfg.Service.Type).To(Equal(config.HeadlessService))
+			})
+
+			Context("and has the service type set explicitly", func() {
+				BeforeEach(func() {
+					svc.Extensions = map[string]interface{}{
+						config.K8SExtensionKey: map[string]interface{}{
+							"workload": map[string]interface{}{"type": "Deployment", "replicas": 1},
+							"service":  map[string]interface{}{"type": "ClusterIP"},
+						},
+					}
+				})
+
+				It("uses the configured service type", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(parsedK8sCfg.Service.Type).To(Equal(config.ClusterIPService))
+				})
+			})
+		})
+
+		Context("for a service which publishes ports", func() {
+			BeforeEach(func() {
+				svc.Ports = []composego.ServicePortConfig{{Target: 8080, Published: 8080}}
+				svc.Expose = composego.StringOrNumberList{"9090"}
+			})
+
+			It("defaults to a cluster IP service", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(parsedK8sCfg.Service.Type).To(Equal(config.ClusterIPService))
+			})
+
+			Context("and uses DNS round-robin endpoint mode", func() {
+				BeforeEach(func() {
+					svc.Deploy = &composego.DeployConfig{EndpointMode: "dnsrr"}
+				})
+
+				It("defaults to a headless service", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(parsedK8sCfg.Service.Type).To(Equal(config.HeadlessService))
+				})
 			})
 		})
 	})
@@ -288,6 +523,24 @@ var _ = Describe("Service Extension", func() {
 			Expect(result).To(BeEquivalentTo(expected))
 		})
 
+		It("overrides base values with explicitly set zero values", func() {
+			basePeriod, zeroPeriod := int64(30), int64(0)
+			automount := false
+
+			k8sBase := config.DefaultSvcK8sConfig()
+			k8sBase.Workload.TerminationGracePeriodSeconds = &basePeriod
+
+			k8sTarget := config.SvcK8sConfig{}
+			k8sTarget.Workload.TerminationGracePeriodSeconds = &zeroPeriod
+			k8sTarget.Workload.AutomountServiceAccountToken = &automount
+
+			result, err := k8sBase.Merge(k8sTarget)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*result.Workload.TerminationGracePeriodSeconds).To(BeZero())
+			Expect(*result.Workload.AutomountServiceAccountToken).To(BeFalse())
+			Expect(basePeriod).To(Equal(int64(30)))
+		})
+
 		Context("Fallback", func() {
 			Context("configs are empty", func() {
 				BeforeEach(func() {
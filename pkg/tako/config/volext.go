@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
+	"strings"
 
 	composego "github.com/compose-spec/compose-go/types"
 	"github.com/go-playground/validator/v10"
@@ -42,6 +43,13 @@ type VolK8sConfig struct {
 	Size         string `yaml:"size" validate:"required,quantity"`
 	StorageClass string `yaml:"storageClass,omitempty"`
 	Selector     string `yaml:"selector,omitempty"`
+	SubPath      string `yaml:"subPath,omitempty" validate:"excluded_with=SubPathExpr"`
+	SubPathExpr  string `yaml:"subPathExpr,omitempty"`
+
+	MountPropagation string `yaml:"mountPropagation,omitempty" validate:"omitempty,oneof=None HostToContainer Bidirectional"`
+	ReadOnly         *bool  `yaml:"readOnly,omitempty"`
+
+	HostPathType string `yaml:"hostPathType,omitempty" validate:"omitempty,oneof=DirectoryOrCreate Directory FileOrCreate File Socket CharDevice BlockDevice"`
 }
 
 // Merge merges in a src volume's K8s config
@@ -78,6 +86,14 @@ func (vkc VolK8sConfig) Validate() error {
 				return fmt.Errorf("%s is required", e.StructNamespace())
 			}
 
+			if e.Tag() == "excluded_with" {
+				return fmt.Errorf("%s can't be set together with %s", e.StructNamespace(), e.Param())
+			}
+
+			if e.Tag() == "oneof" {
+				return fmt.Errorf("%s is invalid, use one of: %s", e.StructNamespace(), strings.ReplaceAll(e.Param(), " ", ", "))
+			}
+
 			if e.Tag() == "quantity" {
 				return fmt.Errorf(
 					"%s is invalid, use a resource quantity format, e.g. 10M, 10Gi, 10Mi",
@@ -67,5 +67,18 @@ var _ = Describe("Volume Extension", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("invalid, use a resource quantity format"))
 		})
+
+		It("doesn't allow both sub path and sub path expression", func() {
+			composeVolExt["subPath"] = "data"
+			composeVolExt["subPathExpr"] = "$(POD_NAME)"
+			_, err := config.VolK8sConfigFromCompose(&composeVol)
+			Expect(err).To(MatchError("VolK8sConfig.SubPath can't be set together with SubPathExpr"))
+		})
+
+		It("validates host path type", func() {
+			composeVolExt["hostPathType"] = "Folder"
+			_, err := config.VolK8sConfigFromCompose(&composeVol)
+			Expect(err).To(MatchError(ContainSubstring("VolK8sConfig.HostPathType is invalid, use one of: DirectoryOrCreate, Directory")))
+		})
 	})
 })
@@ -33,6 +33,9 @@ const (
 
 	// StatefulSetWorkload workload type
 	StatefulSetWorkload WorkloadType = "StatefulSet"
+
+	// JobWorkload workload type
+	JobWorkload WorkloadType = "Job"
 )
 
 // String converts a workload type to a string value
@@ -45,6 +48,7 @@ var workloadTypes = map[WorkloadType]bool{
 	DeploymentWorkload:  true,
 	DaemonSetWorkload:   true,
 	StatefulSetWorkload: true,
+	JobWorkload:         true,
 }
 
 // WorkloadTypeFromValue returns a Workload Type for a given case insensitive value.
@@ -37,15 +37,17 @@ type Converter interface {
 
 // Factory returns a converter
 func Factory(name string, ui kmd.UI) Converter {
+	return FactoryWithOptions(name, ui, kubernetes.ConvertOptions{})
+}
+
+// FactoryWithOptions returns a converter, Kubernetes manifests converter renders with the base conversion options
+func FactoryWithOptions(name string, ui kmd.UI, opt kubernetes.ConvertOptions) Converter {
 	switch name {
 	case "dummy":
 		// Dummy converter example
 		return dummy.New()
 	default:
 		// Kubernetes manifests converter by default
-		if ui == nil {
-			return kubernetes.New()
-		}
-		return kubernetes.NewWithUI(ui)
+		return kubernetes.NewWithOptions(ui, opt)
 	}
 }
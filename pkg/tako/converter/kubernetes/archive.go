@@ -0,0 +1,186 @@
+/**
+ * Copyright 2020 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/appvia/tako/pkg/tako/log"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// printArchive renders objects individually into a temporary directory and packs
+// all rendered manifests into a gzip compressed tarball at opt.OutputArchive
+func printArchive(objects []runtime.Object, opt ConvertOptions, additionalManifests []string, rendered map[string][]byte) error {
+	dir, err := os.MkdirTemp("", "tako-manifests-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// @step unpack the previous archive, so that manifests of preserved services are carried over
+	if len(opt.PreserveServices) > 0 {
+		if err := untar(opt.OutputArchive, dir); err != nil {
+			log.ErrorWithFields(log.Fields{
+				"file": opt.OutputArchive,
+			}, "Failed to read previous manifests archive")
+			return err
+		}
+	}
+
+	// @step render manifests as loose files into the temporary directory
+	archive := opt.OutputArchive
+	opt.OutputArchive = ""
+	opt.OutFile = dir
+	opt.ToStdout = false
+
+	files := map[string][]byte{}
+	if err := PrintList(objects, opt, additionalManifests, files); err != nil {
+		return err
+	}
+
+	data, err := tarball(dir, files)
+	if err != nil {
+		return err
+	}
+
+	// @step write the archive
+	if err := os.MkdirAll(filepath.Dir(archive), 0755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(archive, data, 0644); err != nil {
+		log.ErrorWithFields(log.Fields{
+			"file": archive,
+		}, "Failed to write manifests archive")
+		return err
+	}
+	log.Debugf("%s archive %q created", Name, archive)
+
+	rendered[archive] = data
+
+	return nil
+}
+
+// tarball returns a gzip compressed tarball of files with names relative to the dir.
+// Entries are sorted by name and have a fixed modification time, so that the same
+// manifests always produce an identical archive.
+func tarball(dir string, files map[string][]byte) ([]byte, error) {
+	names := []string{}
+	for file := range files {
+		names = append(names, file)
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gz)
+
+	for _, file := range names {
+		name, err := filepath.Rel(dir, file)
+		if err != nil {
+			return nil, err
+		}
+
+		header := &tar.Header{
+			Name:    filepath.ToSlash(name),
+			Mode:    0644,
+			Size:    int64(len(files[file])),
+			ModTime: time.Unix(0, 0),
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+
+		if _, err := tw.Write(files[file]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// untar unpacks a gzip compressed tarball of manifests into the dir.
+// It's a no-op when the archive doesn't exist yet.
+func untar(archive, dir string) error {
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// @step refuse entries pointing outside of the dir
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("archive entry %q points outside of the manifests directory", header.Name)
+		}
+
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return err
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(file, content, 0644); err != nil {
+			return err
+		}
+	}
+}
@@ -39,7 +39,8 @@ const (
 
 // K8s is a native kubernetes manifests converter
 type K8s struct {
-	UI kmd.UI
+	UI  kmd.UI
+	Opt ConvertOptions // Base conversion options applied when rendering each environment
 }
 
 // New return a native Kubernetes converter
@@ -51,6 +52,11 @@ func NewWithUI(ui kmd.UI) *K8s {
 	return &K8s{UI: ui}
 }
 
+// NewWithOptions returns a native Kubernetes converter rendering with base conversion options
+func NewWithOptions(ui kmd.UI, opt ConvertOptions) *K8s {
+	return &K8s{UI: ui, Opt: opt}
+}
+
 // Render generates outcome
 func (c *K8s) Render(singleFile bool,
 	dir, workDir string,
@@ -89,18 +95,18 @@ func (c *K8s) Render(singleFile bool,
 		}
 
 		// @step generate multiple / single file
+		// Helm charts are always rendered to a directory
 		outFilePath := ""
-		if singleFile {
+		if singleFile && !c.Opt.CreateChart {
 			outFilePath = filepath.Join(outDirPath, singleFileDefaultName)
 		} else {
 			outFilePath = outDirPath
 		}
 
 		// @step kubernetes manifests output options
-		convertOpts := ConvertOptions{
-			InputFiles: files[env],
-			OutFile:    outFilePath,
-		}
+		convertOpts := c.Opt
+		convertOpts.InputFiles = files[env]
+		convertOpts.OutFile = outFilePath
 
 		renderOutputPaths[env] = outFilePath
 
@@ -121,6 +127,9 @@ func (c *K8s) Render(singleFile bool,
 			return nil, err
 		}
 
+		// @step preserve previously rendered manifests of services not managed by the converter
+		convertOpts.PreserveServices = k.Unmanaged
+
 		// @step Produce objects
 		err = PrintList(objects, convertOpts, additionalFiles, rendered)
 		if err != nil {
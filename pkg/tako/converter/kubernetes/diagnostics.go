@@ -0,0 +1,57 @@
+/**
+ * Copyright 2020 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"github.com/appvia/tako/pkg/tako/log"
+)
+
+// Diagnostic is a structured warning raised while converting a compose project service
+type Diagnostic struct {
+	Service string // compose project service name
+	Field   string // compose project service field the warning relates to
+	Message string // human readable warning message
+}
+
+// Diagnostics collects structured warnings raised during transformation
+type Diagnostics struct {
+	items []Diagnostic
+}
+
+// Add appends a diagnostic to the collection
+func (d *Diagnostics) Add(service, field, message string) {
+	d.items = append(d.items, Diagnostic{
+		Service: service,
+		Field:   field,
+		Message: message,
+	})
+}
+
+// Items returns all collected diagnostics in the order they were raised
+func (d *Diagnostics) Items() []Diagnostic {
+	return d.items
+}
+
+// warn logs a warning for a project service field, and records it as a diagnostic
+// when the diagnostics collector is in use
+func (k *Kubernetes) warn(service, field string, fields log.Fields, message string) {
+	log.WarnWithFields(fields, message)
+
+	if k.Diagnostics != nil {
+		k.Diagnostics.Add(service, field, message)
+	}
+}
@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"errors"
+	"sort"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -49,8 +50,10 @@ func handlerFromType(probeType config.ProbeType, pc config.ProbeConfig) v1.Probe
 	case config.ProbeTypeHTTP:
 		return v1.ProbeHandler{
 			HTTPGet: &v1.HTTPGetAction{
-				Path: pc.HTTP.Path,
-				Port: intstr.FromInt(pc.HTTP.Port),
+				Path:        pc.HTTP.Path,
+				Port:        intstr.FromInt(pc.HTTP.Port),
+				Scheme:      httpProbeScheme(pc.HTTP),
+				HTTPHeaders: httpProbeHeaders(pc.HTTP),
 			},
 		}
 	case config.ProbeTypeExec:
@@ -64,3 +67,31 @@ func handlerFromType(probeType config.ProbeType, pc config.ProbeConfig) v1.Probe
 
 	return v1.ProbeHandler{}
 }
+
+// httpProbeScheme returns the http probe scheme, HTTP by default
+func httpProbeScheme(hp config.HTTPProbe) v1.URIScheme {
+	if hp.Scheme == "" {
+		return v1.URISchemeHTTP
+	}
+	return v1.URIScheme(hp.Scheme)
+}
+
+// httpProbeHeaders returns the http probe headers sorted by name, nil if none are defined
+func httpProbeHeaders(hp config.HTTPProbe) []v1.HTTPHeader {
+	if len(hp.Headers) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(hp.Headers))
+	for name := range hp.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	headers := make([]v1.HTTPHeader, 0, len(names))
+	for _, name := range names {
+		headers = append(headers, v1.HTTPHeader{Name: name, Value: hp.Headers[name]})
+	}
+
+	return headers
+}
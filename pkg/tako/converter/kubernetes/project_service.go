@@ -19,6 +19,8 @@ package kubernetes
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/appvia/tako/pkg/tako/config"
@@ -27,9 +29,11 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cast"
 	v1apps "k8s.io/api/apps/v1"
+	v1batch "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 func NewProjectService(svc composego.ServiceConfig) (ProjectService, error) {
@@ -49,6 +53,22 @@ func (p *ProjectService) enabled() bool {
 	return !p.SvcK8sConfig.Disabled
 }
 
+// managed returns whether project service objects should be generated by the converter.
+// Services annotated with `tako.appvia.io/managed: "false"` are maintained manually.
+func (p *ProjectService) managed() bool {
+	v, ok := p.SvcK8sConfig.Workload.Annotations[ManagedAnnotation]
+	if !ok {
+		return true
+	}
+
+	managed, err := strconv.ParseBool(strings.TrimSpace(v))
+	if err != nil {
+		return true
+	}
+
+	return managed
+}
+
 // command returns the workload command
 // When defined via config extension takes precedence over Entrypoint defined by the compose service spec.
 // Compose project service spec Entrypoint is equivalent to a k8s command,
@@ -85,13 +105,138 @@ func (p *ProjectService) commandArgs() []string {
 	return out
 }
 
+// labelAnnotations returns compose project service labels as annotations.
+// Label keys are trimmed and the ones that aren't valid annotation keys are skipped, see invalidLabelKeys.
+func (p *ProjectService) labelAnnotations() map[string]string {
+	out := map[string]string{}
+
+	for key, val := range p.Labels {
+		key = strings.TrimSpace(key)
+		if len(validation.IsQualifiedName(key)) > 0 {
+			continue
+		}
+		out[key] = normalizeAnnotationValue(val)
+	}
+
+	return out
+}
+
+// invalidLabelKeys returns sorted compose project service label keys which aren't valid annotation keys
+func (p *ProjectService) invalidLabelKeys() []string {
+	var keys []string
+
+	for key := range p.Labels {
+		if len(validation.IsQualifiedName(strings.TrimSpace(key))) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// normalizeAnnotationValue returns canonical string form of boolean label values,
+// e.g. `True` becomes `true`. Other values, numeric ones included, are returned as is.
+func normalizeAnnotationValue(val string) string {
+	trimmed := strings.TrimSpace(val)
+
+	if strings.EqualFold(trimmed, "true") || strings.EqualFold(trimmed, "false") {
+		return strings.ToLower(trimmed)
+	}
+
+	return val
+}
+
 // podAnnotations returns the workload pod annotations
 func (p *ProjectService) podAnnotations() map[string]string {
-	out := p.SvcK8sConfig.Workload.Annotations
-	if len(out) == 0 {
-		out = map[string]string{}
+	return configAnnotations(p.SvcK8sConfig.Workload.Annotations, p.SvcK8sConfig.Workload.PodAnnotations)
+}
+
+// workloadAnnotations returns annotations of the workload controller object only, e.g. Deployment
+func (p *ProjectService) workloadAnnotations() map[string]string {
+	return configAnnotations(p.SvcK8sConfig.Workload.WorkloadAnnotations)
+}
+
+// serviceAnnotations returns annotations of the K8s service object only
+func (p *ProjectService) serviceAnnotations() map[string]string {
+	return configAnnotations(p.SvcK8sConfig.Service.Annotations)
+}
+
+// stopSignal returns the compose project service stop signal in its canonical form, e.g. SIGTERM
+func (p *ProjectService) stopSignal() string {
+	signal := strings.ToUpper(strings.TrimSpace(p.StopSignal))
+	if signal != "" && !strings.HasPrefix(signal, "SIG") {
+		signal = "SIG" + signal
 	}
-	return out
+	return signal
+}
+
+// secretEnvName returns the name of the environment variable the compose project service secret
+// should be delivered as, if configured via the `x-k8s.env` secret extension. Empty string otherwise.
+func secretEnvName(secret composego.ServiceSecretConfig) string {
+	ext, ok := secret.Extensions[config.K8SExtensionKey]
+	if !ok {
+		return ""
+	}
+
+	m, err := cast.ToStringMapE(ext)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(cast.ToString(m[SecretEnvExtensionKey]))
+}
+
+// secretTLSFiles returns certificate and key file paths of the compose project secret marked
+// as TLS via the `x-k8s.type` secret extension. Returns false for other secrets.
+func secretTLSFiles(secret composego.SecretConfig) (cert string, key string, ok bool) {
+	ext, found := secret.Extensions[config.K8SExtensionKey]
+	if !found {
+		return "", "", false
+	}
+
+	m, err := cast.ToStringMapE(ext)
+	if err != nil || !strings.EqualFold(strings.TrimSpace(cast.ToString(m[SecretTypeExtensionKey])), SecretTLSType) {
+		return "", "", false
+	}
+
+	cert = strings.TrimSpace(cast.ToString(m[SecretTLSCertExtensionKey]))
+	key = strings.TrimSpace(cast.ToString(m[SecretTLSKeyExtensionKey]))
+
+	return cert, key, true
+}
+
+// secretExternalRef returns External Secrets Operator SecretStore name and remote key set in the project secret `x-k8s` extension, if any
+func secretExternalRef(secret composego.SecretConfig) (store string, remoteKey string) {
+	ext, found := secret.Extensions[config.K8SExtensionKey]
+	if !found {
+		return "", ""
+	}
+
+	m, err := cast.ToStringMapE(ext)
+	if err != nil {
+		return "", ""
+	}
+
+	store = strings.TrimSpace(cast.ToString(m[SecretStoreExtensionKey]))
+	remoteKey = strings.TrimSpace(cast.ToString(m[SecretRemoteKeyExtensionKey]))
+
+	return store, remoteKey
+}
+
+// portName returns container port name set in the service port `x-k8s` extension, if any
+func portName(port composego.ServicePortConfig) string {
+	ext, found := port.Extensions[config.K8SExtensionKey]
+	if !found {
+		return ""
+	}
+
+	m, err := cast.ToStringMapE(ext)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(cast.ToString(m[PortNameExtensionKey]))
 }
 
 // replicas returns number of replicas for given project service
@@ -190,6 +335,14 @@ func (p *ProjectService) tlsSecretName() string {
 	return p.SvcK8sConfig.Service.Expose.TlsSecret
 }
 
+// ingressName returns the ingress name for exposed service, defaults to the service name
+func (p *ProjectService) ingressName() string {
+	if name := strings.TrimSpace(p.SvcK8sConfig.Service.Expose.IngressName); name != "" {
+		return name
+	}
+	return p.Name
+}
+
 // ingressAnnotations returns the ingress annotations for exposed service (to be used in the ingress configuration)
 func (p *ProjectService) ingressAnnotations() map[string]string {
 	annotations := p.SvcK8sConfig.Service.Expose.IngressAnnotations
@@ -199,6 +352,56 @@ func (p *ProjectService) ingressAnnotations() map[string]string {
 	return annotations
 }
 
+// ingressHostAnnotations returns host specific ingress annotations for exposed service, keyed by host name
+func (p *ProjectService) ingressHostAnnotations() map[string]map[string]string {
+	return p.SvcK8sConfig.Service.Expose.IngressHostAnnotations
+}
+
+// updateFailureAction returns compose project service update failure action, e.g. rollback, pause or continue
+func (p *ProjectService) updateFailureAction() string {
+	if p.Deploy == nil || p.Deploy.UpdateConfig == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(p.Deploy.UpdateConfig.FailureAction))
+}
+
+// rollbackConfigAnnotations returns compose project service rollback config as annotations.
+// K8s rolls back a Deployment to the previous revision with the regular rollout strategy, so these are descriptive only.
+func (p *ProjectService) rollbackConfigAnnotations() map[string]string {
+	if p.Deploy == nil || p.Deploy.RollbackConfig == nil {
+		return nil
+	}
+
+	cfg := p.Deploy.RollbackConfig
+	out := map[string]string{}
+
+	if cfg.Parallelism != nil {
+		out[RollbackConfigAnnotationPrefix+"parallelism"] = strconv.FormatUint(*cfg.Parallelism, 10)
+	}
+
+	if cfg.Order != "" {
+		out[RollbackConfigAnnotationPrefix+"order"] = cfg.Order
+	}
+
+	if cfg.Delay != 0 {
+		out[RollbackConfigAnnotationPrefix+"delay"] = cfg.Delay.String()
+	}
+
+	if cfg.FailureAction != "" {
+		out[RollbackConfigAnnotationPrefix+"failure-action"] = cfg.FailureAction
+	}
+
+	if cfg.Monitor != 0 {
+		out[RollbackConfigAnnotationPrefix+"monitor"] = cfg.Monitor.String()
+	}
+
+	if cfg.MaxFailureRatio != 0 {
+		out[RollbackConfigAnnotationPrefix+"max-failure-ratio"] = strconv.FormatFloat(float64(cfg.MaxFailureRatio), 'f', -1, 32)
+	}
+
+	return out
+}
+
 // getKubernetesUpdateStrategy gets update strategy for compose project service
 // Note: it only supports `parallelism` and `order`
 func (p *ProjectService) getKubernetesUpdateStrategy() *v1apps.RollingUpdateDeployment {
@@ -285,6 +488,11 @@ func (p *ProjectService) volumes(project *composego.Project) ([]Volumes, error)
 		temp.PVCSize = k8sVol.Size
 		temp.SelectorValue = k8sVol.Selector
 		temp.StorageClass = k8sVol.StorageClass
+		temp.SubPath = k8sVol.SubPath
+		temp.SubPathExpr = k8sVol.SubPathExpr
+		temp.Propagation = k8sVol.MountPropagation
+		temp.ReadOnly = k8sVol.ReadOnly
+		temp.HostPathType = k8sVol.HostPathType
 		vols[i] = temp
 	}
 
@@ -311,16 +519,20 @@ func (p *ProjectService) placement() map[string]string {
 // - CPU: 0.1, 100m (which is the same as 0.1), 1
 // - Memory: 1, 1M, 1m, 1G, 1Gi
 // - Storage: 128974848, 10M, 100Mi, 1G, 2Gi
-func (p *ProjectService) resourceRequests() (*int64, *int64, *int64) {
+func (p *ProjectService) resourceRequests() (*int64, *int64, *int64, error) {
 	var memRequest int64
 	var cpuRequest int64
 	var storageRequest int64
+	var err error
 
 	// @step extract requests from deploy block if present
 	if p.Deploy != nil && p.Deploy.Resources.Reservations != nil {
 		memRequest = int64(p.Deploy.Resources.Reservations.MemoryBytes)
-		cpu, _ := resource.ParseQuantity(p.Deploy.Resources.Reservations.NanoCPUs)
-		cpuRequest = cpu.ToDec().MilliValue()
+		if val := p.Deploy.Resources.Reservations.NanoCPUs; val != "" {
+			if cpuRequest, err = parseCPU(val); err != nil {
+				return nil, nil, nil, err
+			}
+		}
 	}
 
 	if val := p.SvcK8sConfig.Workload.Resource.Memory; val != "" {
@@ -329,8 +541,9 @@ func (p *ProjectService) resourceRequests() (*int64, *int64, *int64) {
 	}
 
 	if val := p.SvcK8sConfig.Workload.Resource.CPU; val != "" {
-		v, _ := resource.ParseQuantity(val)
-		cpuRequest = v.ToDec().MilliValue()
+		if cpuRequest, err = parseCPU(val); err != nil {
+			return nil, nil, nil, err
+		}
 	}
 
 	if val := p.SvcK8sConfig.Workload.Resource.Storage; val != "" {
@@ -338,7 +551,7 @@ func (p *ProjectService) resourceRequests() (*int64, *int64, *int64) {
 		storageRequest, _ = v.AsInt64()
 	}
 
-	return &memRequest, &cpuRequest, &storageRequest
+	return &memRequest, &cpuRequest, &storageRequest, nil
 }
 
 // resourceLimits returns workload resource limits (memory & cpu)
@@ -351,15 +564,19 @@ func (p *ProjectService) resourceRequests() (*int64, *int64, *int64) {
 // - CPU: 0.1, 100m (which is the same as 0.1), 1
 // - Memory: 1, 1M, 1m, 1G, 1Gi
 // - Storage: 128974848, 10M, 100Mi, 1G, 2Gi
-func (p *ProjectService) resourceLimits() (*int64, *int64, *int64) {
+func (p *ProjectService) resourceLimits() (*int64, *int64, *int64, error) {
 	var memLimit int64
 	var cpuLimit int64
 	var storageLimit int64
+	var err error
 
 	// @step extract limits from deploy block if present
 	if p.Deploy != nil && p.Deploy.Resources.Limits != nil {
-		cpu, _ := resource.ParseQuantity(p.Deploy.Resources.Limits.NanoCPUs)
-		cpuLimit = cpu.ToDec().MilliValue()
+		if val := p.Deploy.Resources.Limits.NanoCPUs; val != "" {
+			if cpuLimit, err = parseCPU(val); err != nil {
+				return nil, nil, nil, err
+			}
+		}
 	}
 
 	if val := p.SvcK8sConfig.Workload.Resource.MaxMemory; val != "" {
@@ -368,8 +585,9 @@ func (p *ProjectService) resourceLimits() (*int64, *int64, *int64) {
 	}
 
 	if val := p.SvcK8sConfig.Workload.Resource.MaxCPU; val != "" {
-		v, _ := resource.ParseQuantity(val)
-		cpuLimit = v.ToDec().MilliValue()
+		if cpuLimit, err = parseCPU(val); err != nil {
+			return nil, nil, nil, err
+		}
 	}
 
 	if val := p.SvcK8sConfig.Workload.Resource.MaxStorage; val != "" {
@@ -377,7 +595,7 @@ func (p *ProjectService) resourceLimits() (*int64, *int64, *int64) {
 		storageLimit, _ = v.AsInt64()
 	}
 
-	return &memLimit, &cpuLimit, &storageLimit
+	return &memLimit, &cpuLimit, &storageLimit, nil
 }
 
 // runAsUser returns pod security context runAsUser value
@@ -395,6 +613,121 @@ func (p *ProjectService) fsGroup() *int64 {
 	return p.SvcK8sConfig.Workload.PodSecurity.FsGroup
 }
 
+// terminationGracePeriodSeconds returns pod termination grace period override for project service
+func (p *ProjectService) terminationGracePeriodSeconds() *int64 {
+	return p.SvcK8sConfig.Workload.TerminationGracePeriodSeconds
+}
+
+// automountServiceAccountToken returns whether the service account token should be automatically mounted into the pod
+func (p *ProjectService) automountServiceAccountToken() *bool {
+	return p.SvcK8sConfig.Workload.AutomountServiceAccountToken
+}
+
+// hostUsers returns whether the pod should use the host user namespace, nil if not specified
+func (p *ProjectService) hostUsers() *bool {
+	return p.SvcK8sConfig.Workload.HostUsers
+}
+
+// updateStrategy returns deployment update strategy type for project service, empty if not specified
+func (p *ProjectService) updateStrategy() v1apps.DeploymentStrategyType {
+	return v1apps.DeploymentStrategyType(p.SvcK8sConfig.Workload.UpdateStrategy)
+}
+
+// daemonSetUpdateStrategy returns DaemonSet update strategy for project service, empty if not specified
+func (p *ProjectService) daemonSetUpdateStrategy() v1apps.DaemonSetUpdateStrategy {
+	workload := p.SvcK8sConfig.Workload
+
+	switch workload.UpdateStrategy {
+	case string(v1apps.OnDeleteDaemonSetStrategyType):
+		return v1apps.DaemonSetUpdateStrategy{Type: v1apps.OnDeleteDaemonSetStrategyType}
+	case string(v1apps.RecreateDeploymentStrategyType):
+		// not supported by DaemonSets, reported during conversion
+		return v1apps.DaemonSetUpdateStrategy{}
+	}
+
+	if workload.RollingUpdateMaxUnavailable != "" {
+		maxUnavailable := intstr.Parse(workload.RollingUpdateMaxUnavailable)
+
+		return v1apps.DaemonSetUpdateStrategy{
+			Type: v1apps.RollingUpdateDaemonSetStrategyType,
+			RollingUpdate: &v1apps.RollingUpdateDaemonSet{
+				MaxUnavailable: &maxUnavailable,
+			},
+		}
+	}
+
+	if workload.UpdateStrategy == string(v1apps.RollingUpdateDaemonSetStrategyType) {
+		return v1apps.DaemonSetUpdateStrategy{Type: v1apps.RollingUpdateDaemonSetStrategyType}
+	}
+
+	return v1apps.DaemonSetUpdateStrategy{}
+}
+
+// jobCounts returns Job completions and parallelism for project service, defaulting to the given number of replicas
+func (p *ProjectService) jobCounts(replicas int) (completions, parallelism int32) {
+	completions, parallelism = int32(replicas), int32(replicas)
+
+	if c := p.SvcK8sConfig.Workload.Job.Completions; c != nil {
+		completions = int32(*c)
+	}
+
+	if pl := p.SvcK8sConfig.Workload.Job.Parallelism; pl != nil {
+		parallelism = int32(*pl)
+	}
+
+	return completions, parallelism
+}
+
+// jobCompletionMode returns Job completion mode for project service, nil if not specified
+func (p *ProjectService) jobCompletionMode() *v1batch.CompletionMode {
+	mode := p.SvcK8sConfig.Workload.Job.CompletionMode
+	if mode == "" {
+		return nil
+	}
+
+	completionMode := v1batch.CompletionMode(mode)
+	return &completionMode
+}
+
+// pvcRetentionPolicy returns StatefulSet PVC retention policy for project service, nil if not specified
+func (p *ProjectService) pvcRetentionPolicy() *v1apps.StatefulSetPersistentVolumeClaimRetentionPolicy {
+	policy := p.SvcK8sConfig.Workload.StatefulSet.PVCRetentionPolicy
+	if policy.WhenDeleted == "" && policy.WhenScaled == "" {
+		return nil
+	}
+
+	// K8s retains PVCs by default
+	out := &v1apps.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: v1apps.RetainPersistentVolumeClaimRetentionPolicyType,
+		WhenScaled:  v1apps.RetainPersistentVolumeClaimRetentionPolicyType,
+	}
+
+	if policy.WhenDeleted != "" {
+		out.WhenDeleted = v1apps.PersistentVolumeClaimRetentionPolicyType(policy.WhenDeleted)
+	}
+
+	if policy.WhenScaled != "" {
+		out.WhenScaled = v1apps.PersistentVolumeClaimRetentionPolicyType(policy.WhenScaled)
+	}
+
+	return out
+}
+
+// boundTokens returns Service Account tokens to be projected into the project service pod
+func (p *ProjectService) boundTokens() []config.BoundToken {
+	return p.SvcK8sConfig.Workload.BoundTokens
+}
+
+// envFrom returns ConfigMaps and Secrets the project service imports all keys from as environment variables
+func (p *ProjectService) envFrom() []config.EnvFrom {
+	return p.SvcK8sConfig.Workload.EnvFrom
+}
+
+// rbacRules returns the access rules granted to the project service's Service Account
+func (p *ProjectService) rbacRules() []config.RBACRule {
+	return p.SvcK8sConfig.Workload.RBAC.Rules
+}
+
 // imagePullPolicy returns image PullPolicy for project service
 func (p *ProjectService) imagePullPolicy() v1.PullPolicy {
 	return v1.PullPolicy(p.SvcK8sConfig.Workload.ImagePull.Policy)
@@ -410,6 +743,39 @@ func (p *ProjectService) serviceAccountName() string {
 	return p.SvcK8sConfig.Workload.ServiceAccountName
 }
 
+// createServiceAccount tells whether the service account should be created, or is managed externally and only referenced
+func (p *ProjectService) createServiceAccount() bool {
+	create := p.SvcK8sConfig.Workload.ServiceAccount.Create
+	return create == nil || *create
+}
+
+// networkPolicyEnabled tells whether Network Policies should be generated for and applied to the project service
+func (p *ProjectService) networkPolicyEnabled() bool {
+	enabled := p.SvcK8sConfig.Service.NetworkPolicy.Enabled
+	return enabled == nil || *enabled
+}
+
+// networkAliases returns sorted, unique network aliases of the project service across all attached networks
+func (p *ProjectService) networkAliases() []string {
+	aliases := []string{}
+
+	for _, network := range p.Networks {
+		if network == nil {
+			continue
+		}
+
+		for _, alias := range network.Aliases {
+			if alias != p.Name && !contains(aliases, alias) {
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+
+	sort.Strings(aliases)
+
+	return aliases
+}
+
 // restartPolicy returns workload restart policy
 func (p *ProjectService) restartPolicy() (v1.RestartPolicy, error) {
 	return toV1RestartPolicy(p.SvcK8sConfig.Workload.RestartPolicy)
@@ -258,6 +258,54 @@ var _ = Describe("ProjectService", func() {
 		})
 	})
 
+	Describe("labelAnnotations", func() {
+		JustBeforeEach(func() {
+			projectService.Labels = composego.Labels{
+				"replicas":          "1e+06",
+				"commit":            "1234e56",
+				"enabled":           "True",
+				"version":           "1.10",
+				" app.io/team ":     "payments",
+				"not a valid key!":  "value",
+				"app.io/path/extra": "value",
+			}
+		})
+
+		It("normalizes boolean values and keeps other values as is", func() {
+			annotations := projectService.labelAnnotations()
+			Expect(annotations).To(HaveKeyWithValue("replicas", "1e+06"))
+			Expect(annotations).To(HaveKeyWithValue("commit", "1234e56"))
+			Expect(annotations).To(HaveKeyWithValue("enabled", "true"))
+			Expect(annotations).To(HaveKeyWithValue("version", "1.10"))
+		})
+
+		It("trims keys and skips illegal ones", func() {
+			annotations := projectService.labelAnnotations()
+			Expect(annotations).To(HaveKeyWithValue("app.io/team", "payments"))
+			Expect(annotations).To(HaveLen(5))
+			Expect(projectService.invalidLabelKeys()).To(Equal([]string{"app.io/path/extra", "not a valid key!"}))
+		})
+	})
+
+	Describe("stopSignal", func() {
+
+		When("stop signal is specified without SIG prefix", func() {
+			JustBeforeEach(func() {
+				projectService.StopSignal = "term"
+			})
+
+			It("returns the stop signal in its canonical form", func() {
+				Expect(projectService.stopSignal()).To(Equal("SIGTERM"))
+			})
+		})
+
+		When("stop signal is not specified", func() {
+			It("returns an empty string", func() {
+				Expect(projectService.stopSignal()).To(BeEmpty())
+			})
+		})
+	})
+
 	Describe("replicas", func() {
 
 		replicas := 10
@@ -425,6 +473,28 @@ var _ = Describe("ProjectService", func() {
 				)
 			})
 		})
+
+		for _, mode := range []string{"replicated-job", "global-job"} {
+			mode := mode
+
+			Context("when deploy block `mode` defined as `"+mode+"`", func() {
+				JustBeforeEach(func() {
+					svc := projectService.ServiceConfig
+					svc.Deploy = &composego.DeployConfig{
+						Mode: mode,
+					}
+					svc.Extensions = nil
+
+					var err error
+					projectService, err = NewProjectService(svc)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("maps to a Job workload", func() {
+					Expect(projectService.workloadType()).To(Equal(config.JobWorkload))
+				})
+			})
+		}
 	})
 
 	Describe("serviceType", func() {
@@ -819,12 +889,41 @@ var _ = Describe("ProjectService", func() {
 		Context("not specified by deploy block", func() {
 			When("not specified via extension", func() {
 				It("returns resource request as zero values", func() {
-					mem, cpu, storage := projectService.resourceRequests()
+					mem, cpu, storage, err := projectService.resourceRequests()
+					Expect(err).NotTo(HaveOccurred())
 					Expect(*mem).To(BeEquivalentTo(0))
 					Expect(*cpu).To(BeEquivalentTo(0))
 					Expect(*storage).To(BeEquivalentTo(0))
 				})
 			})
+
+			When("specified by legacy top level keys", func() {
+				It("returns resource request as defined by the legacy keys", func() {
+					ps, err := NewProjectService(composego.ServiceConfig{
+						Name:           projectServiceName,
+						MemReservation: composego.UnitBytes(128 * 1024 * 1024),
+						CPUShares:      512,
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					mem, cpu, _, err := ps.resourceRequests()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(*mem).To(BeEquivalentTo(134217728))
+					Expect(*cpu).To(BeEquivalentTo(500))
+				})
+
+				It("requests at least 1 millicpu for low cpu shares", func() {
+					ps, err := NewProjectService(composego.ServiceConfig{
+						Name:      projectServiceName,
+						CPUShares: 1,
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					_, cpu, _, err := ps.resourceRequests()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(*cpu).To(BeEquivalentTo(1))
+				})
+			})
 		})
 
 		Context("specified by deploy block", func() {
@@ -841,7 +940,8 @@ var _ = Describe("ProjectService", func() {
 
 			When("not specified via extension", func() {
 				It("returns resource request as defined in deploy block", func() {
-					mem, cpu, storage := projectService.resourceRequests()
+					mem, cpu, storage, err := projectService.resourceRequests()
+					Expect(err).NotTo(HaveOccurred())
 					Expect(*mem).To(BeEquivalentTo(1000))
 					Expect(*cpu).To(BeEquivalentTo(100))
 					Expect(*storage).To(BeEquivalentTo(0))
@@ -854,18 +954,42 @@ var _ = Describe("ProjectService", func() {
 				})
 
 				It("returns CPU request as defined by the extension", func() {
-					_, cpu, _ := projectService.resourceRequests()
+					_, cpu, _, err := projectService.resourceRequests()
+					Expect(err).NotTo(HaveOccurred())
 					Expect(*cpu).To(BeEquivalentTo(200))
 				})
 			})
 
+			When("CPU request in deploy block is invalid", func() {
+				It("returns an error", func() {
+					projectService.Deploy.Resources.Reservations.NanoCPUs = "1Gi"
+
+					_, _, _, err := projectService.resourceRequests()
+					Expect(err).To(MatchError(`invalid CPU value "1Gi", use a number of CPUs, e.g. 0.5, or millicpu, e.g. 500m`))
+				})
+			})
+
+			When("only memory request is specified in deploy block", func() {
+				BeforeEach(func() {
+					deploy.Resources.Reservations.NanoCPUs = ""
+				})
+
+				It("returns zero CPU request", func() {
+					mem, cpu, _, err := projectService.resourceRequests()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(*mem).To(BeEquivalentTo(1000))
+					Expect(*cpu).To(BeEquivalentTo(0))
+				})
+			})
+
 			When("Memory request is specified via extension", func() {
 				BeforeEach(func() {
 					svcK8sConfig.Workload.Resource.Memory = "1M"
 				})
 
 				It("returns Memory request as defined by the extension", func() {
-					mem, _, _ := projectService.resourceRequests()
+					mem, _, _, err := projectService.resourceRequests()
+					Expect(err).NotTo(HaveOccurred())
 					Expect(*mem).To(BeEquivalentTo(1000000))
 				})
 			})
@@ -876,7 +1000,8 @@ var _ = Describe("ProjectService", func() {
 				})
 
 				It("returns Storage request as defined by the extension", func() {
-					_, _, storage := projectService.resourceRequests()
+					_, _, storage, err := projectService.resourceRequests()
+					Expect(err).NotTo(HaveOccurred())
 					Expect(*storage).To(BeEquivalentTo(500000000))
 				})
 			})
@@ -887,12 +1012,29 @@ var _ = Describe("ProjectService", func() {
 		Context("not specified by deploy block", func() {
 			When("not specified via extension", func() {
 				It("returns resource limits as zero values", func() {
-					mem, cpu, storage := projectService.resourceLimits()
+					mem, cpu, storage, err := projectService.resourceLimits()
+					Expect(err).NotTo(HaveOccurred())
 					Expect(*mem).To(BeEquivalentTo(0))
 					Expect(*cpu).To(BeEquivalentTo(0))
 					Expect(*storage).To(BeEquivalentTo(0))
 				})
 			})
+
+			When("specified by legacy top level keys", func() {
+				It("returns resource limits as defined by the legacy keys", func() {
+					ps, err := NewProjectService(composego.ServiceConfig{
+						Name:     projectServiceName,
+						MemLimit: composego.UnitBytes(256 * 1024 * 1024),
+						CPUS:     0.5,
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					mem, cpu, _, err := ps.resourceLimits()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(*mem).To(BeEquivalentTo(268435456))
+					Expect(*cpu).To(BeEquivalentTo(500))
+				})
+			})
 		})
 
 		Context("specified by deploy block", func() {
@@ -907,22 +1049,51 @@ var _ = Describe("ProjectService", func() {
 				}
 			})
 
+			When("legacy top level keys are specified too", func() {
+				It("prefers the deploy block", func() {
+					ps, err := NewProjectService(composego.ServiceConfig{
+						Name:     projectServiceName,
+						Deploy:   deploy,
+						MemLimit: composego.UnitBytes(256 * 1024 * 1024),
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					mem, _, _, err := ps.resourceLimits()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(*mem).To(BeEquivalentTo(1000))
+				})
+			})
+
 			When("not specified via extension", func() {
 				It("returns resource limit as defined in deploy block", func() {
-					mem, cpu, storage := projectService.resourceLimits()
+					mem, cpu, storage, err := projectService.resourceLimits()
+					Expect(err).NotTo(HaveOccurred())
 					Expect(*mem).To(BeEquivalentTo(1000))
 					Expect(*cpu).To(BeEquivalentTo(100))
 					Expect(*storage).To(BeEquivalentTo(0))
 				})
 			})
 
+			When("only memory limit is specified in deploy block", func() {
+				BeforeEach(func() {
+					deploy.Resources.Limits.NanoCPUs = ""
+				})
+
+				It("returns zero CPU limit", func() {
+					_, cpu, _, err := projectService.resourceLimits()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(*cpu).To(BeEquivalentTo(0))
+				})
+			})
+
 			When("CPU limit is specified via extension", func() {
 				BeforeEach(func() {
 					svcK8sConfig.Workload.Resource.MaxCPU = "0.2"
 				})
 
 				It("returns CPU limit as defined by the extension", func() {
-					_, cpu, _ := projectService.resourceLimits()
+					_, cpu, _, err := projectService.resourceLimits()
+					Expect(err).NotTo(HaveOccurred())
 					Expect(*cpu).To(BeEquivalentTo(200))
 				})
 			})
@@ -933,7 +1104,8 @@ var _ = Describe("ProjectService", func() {
 				})
 
 				It("returns Memory limit as defined by the extension", func() {
-					mem, _, _ := projectService.resourceLimits()
+					mem, _, _, err := projectService.resourceLimits()
+					Expect(err).NotTo(HaveOccurred())
 					Expect(*mem).To(BeEquivalentTo(200))
 				})
 			})
@@ -944,7 +1116,8 @@ var _ = Describe("ProjectService", func() {
 				})
 
 				It("returns Ephemeral Storage limit as defined by the extension", func() {
-					_, _, storage := projectService.resourceLimits()
+					_, _, storage, err := projectService.resourceLimits()
+					Expect(err).NotTo(HaveOccurred())
 					Expect(*storage).To(BeEquivalentTo(1000000000))
 				})
 			})
@@ -1301,7 +1474,7 @@ var _ = Describe("ProjectService", func() {
 				Expect(cmp.Diff(result, &v1.Probe{
 					ProbeHandler: v1.ProbeHandler{
 						Exec: &v1.ExecAction{
-							Command: []string{"my command"},
+							Command: []string{"sh", "-c", "my command"},
 						},
 					},
 					TimeoutSeconds:      10,
@@ -1313,6 +1486,69 @@ var _ = Describe("ProjectService", func() {
 			})
 		})
 
+		Context("when healthcheck test is in exec form", func() {
+			BeforeEach(func() {
+				healthcheck = composego.HealthCheckConfig{
+					Test: composego.HealthCheckTest{"CMD", "curl", "-f", "http://localhost/health"},
+				}
+			})
+
+			It("passes the arguments directly to the exec command", func() {
+				result, err := projectService.LivenessProbe()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Exec.Command).To(Equal([]string{"curl", "-f", "http://localhost/health"}))
+			})
+		})
+
+		Context("when healthcheck test is in shell form", func() {
+			BeforeEach(func() {
+				healthcheck = composego.HealthCheckConfig{
+					Test: composego.HealthCheckTest{"CMD-SHELL", "curl -f http://localhost/health || exit 1"},
+				}
+			})
+
+			It("wraps the command in a shell", func() {
+				result, err := projectService.LivenessProbe()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Exec.Command).To(Equal([]string{"sh", "-c", "curl -f http://localhost/health || exit 1"}))
+			})
+		})
+
+		Context("when custom thresholds and timings are defined via extension", func() {
+			BeforeEach(func() {
+				svcK8sConfig.Workload.LivenessProbe.Type = config.ProbeTypeTCP.String()
+				svcK8sConfig.Workload.LivenessProbe.TCP.Port = 8080
+				svcK8sConfig.Workload.LivenessProbe.InitialDelay = 45 * time.Second
+				svcK8sConfig.Workload.LivenessProbe.Period = 20 * time.Second
+				svcK8sConfig.Workload.LivenessProbe.Timeout = 5 * time.Second
+				svcK8sConfig.Workload.LivenessProbe.FailureThreshold = 6
+			})
+
+			It("returns a Probe with those values", func() {
+				result, err := projectService.LivenessProbe()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.InitialDelaySeconds).To(BeEquivalentTo(45))
+				Expect(result.PeriodSeconds).To(BeEquivalentTo(20))
+				Expect(result.TimeoutSeconds).To(BeEquivalentTo(5))
+				Expect(result.FailureThreshold).To(BeEquivalentTo(6))
+				Expect(result.SuccessThreshold).To(BeEquivalentTo(1))
+			})
+		})
+
+		Context("when healthcheck test is NONE", func() {
+			BeforeEach(func() {
+				healthcheck = composego.HealthCheckConfig{
+					Test: composego.HealthCheckTest{"NONE"},
+				}
+			})
+
+			It("doesn't return a Probe", func() {
+				result, err := projectService.LivenessProbe()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(BeNil())
+			})
+		})
+
 		Describe("validations", func() {
 			BeforeEach(func() {
 				svcK8sConfig.Workload.LivenessProbe.Type = config.ProbeTypeExec.String()
@@ -1333,6 +1569,17 @@ var _ = Describe("ProjectService", func() {
 				})
 			})
 
+			When("success threshold is greater than 1", func() {
+				It("enforces a success threshold of 1", func() {
+					lp := config.DefaultLivenessProbe()
+					lp.SuccessThreshold = 2
+
+					result, err := LivenessProbeToV1Probe(lp)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.SuccessThreshold).To(BeEquivalentTo(1))
+				})
+			})
+
 			When("any of time based parameters is set to 0", func() {
 				BeforeEach(func() {
 					svcK8sConfig.Workload.LivenessProbe.Type = config.ProbeTypeExec.String()
@@ -1347,6 +1594,24 @@ var _ = Describe("ProjectService", func() {
 		})
 	})
 
+	Describe("readiness probe", func() {
+		Context("when custom thresholds are defined via extension", func() {
+			BeforeEach(func() {
+				svcK8sConfig.Workload.ReadinessProbe.Type = config.ProbeTypeTCP.String()
+				svcK8sConfig.Workload.ReadinessProbe.TCP.Port = 8080
+				svcK8sConfig.Workload.ReadinessProbe.SuccessThreshold = 2
+				svcK8sConfig.Workload.ReadinessProbe.FailureThreshold = 5
+			})
+
+			It("keeps the success threshold as configured", func() {
+				result, err := projectService.ReadinessProbe()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.SuccessThreshold).To(BeEquivalentTo(2))
+				Expect(result.FailureThreshold).To(BeEquivalentTo(5))
+			})
+		})
+	})
+
 	Describe("livenessHTTPProbe", func() {
 		When("defined via extension", func() {
 			Context("with all the parameters", func() {
@@ -1364,6 +1629,43 @@ var _ = Describe("ProjectService", func() {
 				})
 			})
 
+			Context("with HTTPS scheme and headers", func() {
+				BeforeEach(func() {
+					svcK8sConfig.Workload.LivenessProbe.Type = config.ProbeTypeHTTP.String()
+					svcK8sConfig.Workload.LivenessProbe.HTTP.Path = "/status"
+					svcK8sConfig.Workload.LivenessProbe.HTTP.Port = 8443
+					svcK8sConfig.Workload.LivenessProbe.HTTP.Scheme = "HTTPS"
+					svcK8sConfig.Workload.LivenessProbe.HTTP.Headers = map[string]string{
+						"X-Probe":       "liveness",
+						"Authorization": "Bearer token",
+					}
+				})
+
+				It("returns a handler with the scheme and headers sorted by name", func() {
+					result, err := projectService.LivenessProbe()
+					Expect(err).To(BeNil())
+					Expect(result.HTTPGet.Scheme).To(Equal(v1.URISchemeHTTPS))
+					Expect(result.HTTPGet.HTTPHeaders).To(Equal([]v1.HTTPHeader{
+						{Name: "Authorization", Value: "Bearer token"},
+						{Name: "X-Probe", Value: "liveness"},
+					}))
+				})
+			})
+
+			Context("without scheme", func() {
+				BeforeEach(func() {
+					svcK8sConfig.Workload.LivenessProbe.Type = config.ProbeTypeHTTP.String()
+					svcK8sConfig.Workload.LivenessProbe.HTTP.Port = 8080
+				})
+
+				It("defaults to HTTP scheme", func() {
+					result, err := projectService.LivenessProbe()
+					Expect(err).To(BeNil())
+					Expect(result.HTTPGet.Scheme).To(Equal(v1.URISchemeHTTP))
+					Expect(result.HTTPGet.HTTPHeaders).To(BeNil())
+				})
+			})
+
 			Context("with missing path", func() {
 				BeforeEach(func() {
 					svcK8sConfig.Workload.LivenessProbe.Type = config.ProbeTypeHTTP.String()
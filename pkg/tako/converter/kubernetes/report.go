@@ -0,0 +1,111 @@
+/**
+ * Copyright 2020 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+
+	"github.com/appvia/tako/pkg/tako/log"
+	composego "github.com/compose-spec/compose-go/types"
+	"gopkg.in/yaml.v3"
+)
+
+// IgnoredField describes a compose project service field dropped during conversion
+type IgnoredField struct {
+	Field  string `yaml:"field"`  // compose project service field name
+	Reason string `yaml:"reason"` // why the field has been ignored
+}
+
+// ConversionReport summarises compose project service fields ignored during conversion
+type ConversionReport struct {
+	Services map[string][]IgnoredField `yaml:"services"`
+}
+
+// Add records an ignored field for a project service
+func (r *ConversionReport) Add(service, field, reason string) {
+	if r.Services == nil {
+		r.Services = map[string][]IgnoredField{}
+	}
+
+	r.Services[service] = append(r.Services[service], IgnoredField{
+		Field:  field,
+		Reason: reason,
+	})
+}
+
+// Ignored returns fields ignored for a project service in the order they were recorded
+func (r *ConversionReport) Ignored(service string) []IgnoredField {
+	return r.Services[service]
+}
+
+// Write saves the report as YAML to the given file
+func (r *ConversionReport) Write(file string) error {
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, data, 0644)
+}
+
+// unsupportedServiceFields lists compose project service fields without a K8s equivalent
+var unsupportedServiceFields = []struct {
+	field  string
+	reason string
+	isSet  func(svc composego.ServiceConfig) bool
+}{
+	{"cgroup_parent", "Pod cgroups are managed by the kubelet", func(svc composego.ServiceConfig) bool { return svc.CgroupParent != "" }},
+	{"links", "Services are discoverable by name via K8s services", func(svc composego.ServiceConfig) bool { return len(svc.Links) > 0 }},
+	{"external_links", "Services are discoverable by name via K8s services", func(svc composego.ServiceConfig) bool { return len(svc.ExternalLinks) > 0 }},
+	{"dns", "Pod DNS is configured by the cluster", func(svc composego.ServiceConfig) bool { return len(svc.DNS) > 0 }},
+	{"dns_search", "Pod DNS is configured by the cluster", func(svc composego.ServiceConfig) bool { return len(svc.DNSSearch) > 0 }},
+	{"dns_opt", "Pod DNS is configured by the cluster", func(svc composego.ServiceConfig) bool { return len(svc.DNSOpts) > 0 }},
+	{"extra_hosts", "Extra hosts aren't converted to pod host aliases yet", func(svc composego.ServiceConfig) bool { return len(svc.ExtraHosts) > 0 }},
+	{"ulimits", "K8s doesn't support per container ulimits", func(svc composego.ServiceConfig) bool { return len(svc.Ulimits) > 0 }},
+	{"sysctls", "Sysctls aren't converted to pod security context sysctls yet", func(svc composego.ServiceConfig) bool { return len(svc.Sysctls) > 0 }},
+	{"security_opt", "Container security options aren't supported, use x-k8s pod security instead", func(svc composego.ServiceConfig) bool { return len(svc.SecurityOpt) > 0 }},
+	{"network_mode", "Pods always use the cluster network", func(svc composego.ServiceConfig) bool { return svc.NetworkMode != "" }},
+	{"mac_address", "Pods always use the cluster network", func(svc composego.ServiceConfig) bool { return svc.MacAddress != "" }},
+	{"pid", "Host and container PID namespaces aren't supported", func(svc composego.ServiceConfig) bool { return svc.Pid != "" }},
+	{"ipc", "Host and container IPC namespaces aren't supported", func(svc composego.ServiceConfig) bool { return svc.Ipc != "" }},
+	{"userns_mode", "User namespaces aren't supported", func(svc composego.ServiceConfig) bool { return svc.UserNSMode != "" }},
+	{"runtime", "Container runtimes are selected by the cluster", func(svc composego.ServiceConfig) bool { return svc.Runtime != "" }},
+	{"shm_size", "Shared memory size isn't supported", func(svc composego.ServiceConfig) bool { return svc.ShmSize != "" }},
+	{"oom_kill_disable", "OOM killer is managed by the kubelet", func(svc composego.ServiceConfig) bool { return svc.OomKillDisable }},
+	{"oom_score_adj", "OOM score is managed by the kubelet", func(svc composego.ServiceConfig) bool { return svc.OomScoreAdj != 0 }},
+	{"cpuset", "CPU pinning is managed by the kubelet", func(svc composego.ServiceConfig) bool { return svc.CPUSet != "" }},
+	{"logging", "Container logs are collected by the cluster", func(svc composego.ServiceConfig) bool { return svc.Logging != nil }},
+}
+
+// reportUnsupportedFields warns about, and records in the conversion report when in use,
+// compose project service fields which are dropped during conversion
+func (k *Kubernetes) reportUnsupportedFields(projectService ProjectService) {
+	for _, f := range unsupportedServiceFields {
+		if !f.isSet(projectService.ServiceConfig) {
+			continue
+		}
+
+		k.warn(projectService.Name, f.field, log.Fields{
+			"project-service": projectService.Name,
+			"field":           f.field,
+		}, "Compose field isn't supported and will be ignored: "+f.reason)
+
+		if k.Report != nil {
+			k.Report.Add(projectService.Name, f.field, f.reason)
+		}
+	}
+}
@@ -21,6 +21,8 @@
 package kubernetes
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -39,26 +41,63 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
 	v1apps "k8s.io/api/apps/v1"
 	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	v1batch "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const DefaultIngressBackendKeyword = "default"
 
 // Kubernetes transformer
 type Kubernetes struct {
-	Opt      ConvertOptions     // user provided options from the command line
-	Project  *composego.Project // docker compose project
-	Excluded []string           // docker compose service names that should be excluded
-	UI       kmd.UI
+	Opt         ConvertOptions     // user provided options from the command line
+	Project     *composego.Project // docker compose project
+	Excluded    []string           // docker compose service names or glob patterns, e.g. worker-*, that should be excluded
+	UI          kmd.UI
+	Diagnostics *Diagnostics      // optional collector of structured warnings raised during transformation
+	Unmanaged   []string          // project service names skipped during transformation as not managed by the converter
+	Report      *ConversionReport // optional summary of compose fields ignored during transformation
+
+	registryAuthSecret string // name of the image pull secret generated from project registry credentials, if configured
+}
+
+// TransformWithDiagnostics converts compose project to set of k8s objects and returns
+// structured warnings raised during transformation alongside the objects
+func (k *Kubernetes) TransformWithDiagnostics() ([]runtime.Object, []Diagnostic, error) {
+	// @step start every run with a fresh collector, so that diagnostics of previous runs aren't returned
+	k.Diagnostics = &Diagnostics{}
+
+	objects, err := k.Transform()
+	if err != nil {
+		return nil, k.Diagnostics.Items(), err
+	}
+
+	return objects, k.Diagnostics.Items(), nil
+}
+
+// TransformWithReport converts compose project to set of k8s objects and returns
+// a report of compose fields ignored during transformation alongside the objects
+func (k *Kubernetes) TransformWithReport() ([]runtime.Object, *ConversionReport, error) {
+	// @step start every run with a fresh report, so that fields ignored in previous runs aren't reported
+	k.Report = &ConversionReport{}
+
+	objects, err := k.Transform()
+	if err != nil {
+		return nil, k.Report, err
+	}
+
+	return objects, k.Report, nil
 }
 
 // Transform converts compose project to set of k8s objects
@@ -67,11 +106,27 @@ type Kubernetes struct {
 func (k *Kubernetes) Transform() ([]runtime.Object, error) {
 	// holds all the converted objects
 	var allobjects []runtime.Object
-	var renderedNetworkPolicy runtime.Object
+	var policyNetworks []string
+	var mutableTagServices []string
 
 	sg := k.UI.StepGroup()
 	defer sg.Done()
 
+	// @step reject unknown long names strategies rather than silently truncating names
+	switch k.Opt.LongNames {
+	case "", LongNamesTruncate, LongNamesHash:
+	default:
+		return nil, fmt.Errorf("unsupported long names strategy %q, allowed values: %s, %s", k.Opt.LongNames, LongNamesTruncate, LongNamesHash)
+	}
+
+	// @step start with no unmanaged services, they're collected again on every run
+	k.Unmanaged = nil
+
+	// @step collect ignored compose fields when the report should be written
+	if k.Opt.ReportFile != "" && k.Report == nil {
+		k.Report = &ConversionReport{}
+	}
+
 	// @step iterate over defined secrets and build Secret objects accordingly
 	if k.Project.Secrets != nil && len(k.Project.Secrets) > 0 {
 		stepSecrets := sg.Add("Converting project secrets")
@@ -85,42 +140,78 @@ func (k *Kubernetes) Transform() ([]runtime.Object, error) {
 		for _, item := range secrets {
 			allobjects = append(allobjects, item)
 		}
+		allobjects = append(allobjects, k.createExternalSecrets()...)
 		stepSecrets.Success("Converted project secrets")
 	}
 
+	// @step build image pull secret from project registry credentials if configured,
+	// credentials are resolved once and the secret is referenced by name from every pod
+	k.registryAuthSecret = ""
+	registryAuthSecret, err := k.createRegistryAuthSecret()
+	if err != nil {
+		msg := "Unable to create image pull secret from registry credentials"
+		log.Error(msg)
+		return nil, errors.Wrapf(err, "%s", msg)
+	}
+	if registryAuthSecret != nil {
+		k.registryAuthSecret = registryAuthSecret.Name
+		allobjects = append(allobjects, registryAuthSecret)
+	}
+
 	// @step sort project services by name for consistency
 	sortServices(k.Project)
 
 	// @step iterate over sorted service definitions
 	for _, pSvc := range k.Project.Services {
 		// @step skip service if excluded
-		if contains(k.Excluded, pSvc.Name) {
+		if excludedService(k.Excluded, pSvc.Name) {
 			continue
 		}
 
 		stepSvc := sg.Add(fmt.Sprintf("Converting service: %s", pSvc.Name))
 		var objects []runtime.Object
 
+		// @step load the service x-k8s extension from an external file if referenced
+		extensions, err := k.resolveK8sExtensionRef(pSvc.Extensions)
+		if err != nil {
+			return nil, errors.Wrapf(err, "when parsing service %s extensions", pSvc.Name)
+		}
+
+		// @step apply project level x-k8s defaults under the service's own extension
+		pSvc.Extensions = WithProjectK8sDefaults(extensions, k.Project.Extensions)
+
 		projectService, err := NewProjectService(pSvc)
 		if err != nil {
 			return nil, err
 		}
 
-		// @step skip disabled services
-		if !projectService.enabled() {
+		// @step skip disabled services unless requested to render them
+		if !projectService.enabled() && !k.Opt.RenderDisabled {
 			continue
 		}
 
-		// @step normalise project service name
-		if rfc1123dns(projectService.Name) != projectService.Name {
+		// @step skip services not managed by the converter, their manually maintained manifests are preserved
+		if !projectService.managed() {
+			log.DebugWithFields(log.Fields{
+				"project-service": projectService.Name,
+			}, "Skipping service not managed by the converter")
+
+			k.Unmanaged = append(k.Unmanaged, k.dnsName(projectService.Name))
+			continue
+		}
+
+		// @step normalise project service name, it names the workload and most of the service objects
+		if name := k.dnsName(projectService.Name); name != projectService.Name {
 			log.DebugfWithFields(log.Fields{
 				"project-service": projectService.Name,
-			}, "Compose service name normalised to %q",
-				rfc1123dns(projectService.Name))
+			}, "Compose service name normalised to %q", name)
 
-			projectService.Name = rfc1123dns(projectService.Name)
+			projectService.Name = name
 		}
 
+		// @step report compose fields which have no K8s equivalent
+		k.reportUnsupportedFields(projectService)
+
 		// @step we're not concerned about building & publishing images yet,
 		// but will validate presence of image key for each service.
 		// If there's no "image" key, use the name of the container that's built
@@ -132,6 +223,19 @@ func (k *Kubernetes) Transform() ([]runtime.Object, error) {
 			return nil, fmt.Errorf("image key required within build parameters in order to build and push service '%s'", projectService.Name)
 		}
 
+		// @step collect services referencing images by mutable tags when these are disallowed
+		if k.Opt.DisallowMutableTags && mutableImageTag(projectService.Image) {
+			mutableTagServices = append(mutableTagServices, projectService.Name)
+		}
+
+		// @step report compose labels which can't be rendered as annotations
+		for _, key := range projectService.invalidLabelKeys() {
+			k.warn(projectService.Name, "labels", log.Fields{
+				"project-service": projectService.Name,
+				"label":           key,
+			}, "Label key isn't a valid K8s annotation key and will be ignored")
+		}
+
 		// @step create kubernetes object (never create a pod in isolation!)
 		// https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle/#pod-lifetime
 		objects = k.createKubernetesObjects(projectService)
@@ -163,7 +267,9 @@ func (k *Kubernetes) Transform() ([]runtime.Object, error) {
 				return nil, errors.Wrapf(err, "%s", msg)
 			}
 			if expose != "" {
-				objects = append(objects, k.initIngress(projectService, svc.Spec.Ports[0].Port))
+				for _, ingress := range k.splitIngressPerHost(projectService, k.initIngress(projectService, svc.Spec.Ports[0].Port)) {
+					objects = append(objects, ingress)
+				}
 			}
 		} else if config.ServiceTypesEqual(serviceType, config.HeadlessService) {
 			// No ports defined - creating headless service instead
@@ -171,6 +277,11 @@ func (k *Kubernetes) Transform() ([]runtime.Object, error) {
 			objects = append(objects, svc)
 		}
 
+		// @step create headless services so that project service network aliases resolve to its pods
+		for _, svc := range k.createAliasServices(projectService) {
+			objects = append(objects, svc)
+		}
+
 		// @step updating all objects related to a current compose service
 		if err = k.updateKubernetesObjects(projectService, &objects); err != nil {
 			msg := "Error occurred while transforming Kubernetes objects"
@@ -178,6 +289,11 @@ func (k *Kubernetes) Transform() ([]runtime.Object, error) {
 			return nil, errors.Wrapf(err, "%s", msg)
 		}
 
+		// @step scale down and annotate objects of a disabled service
+		if !projectService.enabled() {
+			objects = k.markDisabled(projectService.Name, objects)
+		}
+
 		stepSvc.Success(fmt.Sprintf("Converted service: %s", pSvc.Name))
 		for _, object := range objects {
 			k.UI.Output(
@@ -188,46 +304,161 @@ func (k *Kubernetes) Transform() ([]runtime.Object, error) {
 			)
 		}
 
-		// @step create network policies if networks defined
-		if len(projectService.Networks) > 0 {
+		// @step collect networks to restrict with network policies, unless service opted out of them
+		if projectService.networkPolicyEnabled() {
 			for name := range projectService.Networks {
+				// @step external networks are managed elsewhere, as are their network policies
+				if k.networkConfig(name).External.External {
+					log.DebugWithFields(log.Fields{
+						"project-service": projectService.Name,
+						"network-name":    name,
+					}, "External network detected, skipping NetworkPolicy generation")
+					continue
+				}
+
 				log.DebugWithFields(log.Fields{
 					"project-service": projectService.Name,
 					"network-name":    name,
 				}, "Network detected and will be converted to equivalent NetworkPolicy")
 
-				np, err := k.createNetworkPolicy(projectService.Name, name)
-				if err != nil {
-					msg := fmt.Sprintf("Unable to create Network Policy for network %v for service %v", name, projectService.Name)
-					log.Error(msg)
-					stepSvc.Error()
-					return nil, errors.Wrapf(err, "%s", msg)
+				if !contains(policyNetworks, name) {
+					policyNetworks = append(policyNetworks, name)
 				}
-				objects = append(objects, np)
-				renderedNetworkPolicy = np
 			}
 		}
 
 		allobjects = append(allobjects, objects...)
 	}
 
-	if renderedNetworkPolicy != nil {
-		sg.Add("Networking").Success()
+	if len(mutableTagServices) > 0 {
+		return nil, fmt.Errorf("untagged or `latest` images aren't allowed, pin image tags for services: %s", strings.Join(mutableTagServices, ", "))
+	}
+
+	// @step create a single network policy per network shared by all services attached to it
+	if len(policyNetworks) > 0 || k.Opt.DefaultDenyNetworkPolicy {
+		stepNetworking := sg.Add("Networking")
+		sort.Strings(policyNetworks)
+
+		if k.Opt.DefaultDenyNetworkPolicy {
+			allobjects = append(allobjects, k.createDefaultDenyNetworkPolicy())
+		}
+
+		for _, name := range policyNetworks {
+			np, err := k.createNetworkPolicy(name)
+			if err != nil {
+				msg := fmt.Sprintf("Unable to create Network Policy for network %v", name)
+				log.Error(msg)
+				stepNetworking.Error()
+				return nil, errors.Wrapf(err, "%s", msg)
+			}
+			allobjects = append(allobjects, np)
+		}
+
+		stepNetworking.Success()
 		k.UI.Output(
-			fmt.Sprintf("rendered %s", renderedNetworkPolicy.GetObjectKind().GroupVersionKind().Kind),
+			"rendered NetworkPolicy",
 			kmd.WithStyle(kmd.LogStyle),
 			kmd.WithIndent(3),
 			kmd.WithIndentChar(kmd.LogIndentChar),
 		)
 	}
 
+	// @step annotate pod templates with a checksum of referenced config data so that its changes roll pods
+	if err := stampConfigChecksum(allobjects); err != nil {
+		return nil, errors.Wrap(err, "Unable to compute config checksum")
+	}
+
+	// @step stamp workloads with a hash of their final spec for change detection
+	if k.Opt.StampSpecHash {
+		if err := stampSpecHash(allobjects); err != nil {
+			return nil, errors.Wrapf(err, "%s", "Could not compute workload spec hash")
+		}
+	}
+
+	// @step set default namespace on objects which don't specify one
+	if k.Opt.DefaultNamespace != "" {
+		setDefaultNamespace(allobjects, k.Opt.DefaultNamespace)
+	}
+
+	// @step ensure ConfigMaps and Secrets aren't rejected by K8s for their size
+	if err := k.checkObjectSizes(allobjects); err != nil {
+		return nil, err
+	}
+
+	// @step ensure workloads select their own pods
+	if err := checkSelectors(allobjects); err != nil {
+		return nil, err
+	}
+
+	// @step validate objects against K8s API rules when requested
+	if k.Opt.Validate {
+		if err := validateObjects(allobjects); err != nil {
+			return nil, err
+		}
+	}
+
+	// @step warn about Services of different project services sharing a name, e.g. via network aliases
+	k.warnServiceNameCollisions(allobjects)
+
 	// @step sort all object so Services are first and remove duplicates
 	k.sortServicesFirst(&allobjects)
 	k.removeDupObjects(&allobjects)
 
+	// @step keep only objects of requested kinds, preserving their order
+	if len(k.Opt.IncludeKinds) > 0 || len(k.Opt.ExcludeKinds) > 0 {
+		filtered, err := filterKinds(allobjects, k.Opt.IncludeKinds, k.Opt.ExcludeKinds)
+		if err != nil {
+			return nil, err
+		}
+		allobjects = filtered
+	}
+
+	// @step write the report of ignored compose fields when requested
+	if k.Opt.ReportFile != "" {
+		if err := k.Report.Write(k.Opt.ReportFile); err != nil {
+			log.ErrorWithFields(log.Fields{
+				"file": k.Opt.ReportFile,
+			}, "Failed to write conversion report")
+			return nil, err
+		}
+	}
+
 	return allobjects, nil
 }
 
+// checkObjectSizes returns an error listing ConfigMaps and Secrets with data exceeding the maximum object size
+func (k *Kubernetes) checkObjectSizes(objects []runtime.Object) error {
+	limit := k.Opt.MaxObjectSize
+	if limit <= 0 {
+		limit = DefaultMaxObjectSize
+	}
+
+	oversized := []string{}
+	for _, obj := range objects {
+		size, name := objectDataSize(obj)
+		if size > limit {
+			oversized = append(oversized, fmt.Sprintf("%s (%d bytes)", name, size))
+		}
+	}
+
+	if len(oversized) > 0 {
+		return fmt.Errorf("objects exceed maximum size of %d bytes, split their source files or directories: %s",
+			limit, strings.Join(oversized, ", "))
+	}
+
+	return nil
+}
+
+// imageName returns workload image name transformed by the configured image name transformer, if any,
+// and prefixed with the image registry, consistently with image names of the Skaffold build artifacts.
+func (k *Kubernetes) imageName(image string) string {
+	if transform := k.Opt.ImageNameTransformer; transform != nil {
+		image = transform(image)
+	}
+
+	return PrefixImageRegistry(image, k.Opt.ImageRegistryPrefix)
+}
+
 // initPodSpec creates the pod specification
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L129
 func (k *Kubernetes) initPodSpec(projectService ProjectService) v1.PodSpec {
@@ -237,6 +468,7 @@ func (k *Kubernetes) initPodSpec(projectService ProjectService) v1.PodSpec {
 	if image == "" {
 		image = projectService.Name
 	}
+	image = k.imageName(image)
 
 	// @step get image pull secret for the pod
 	pullSecret := projectService.imagePullSecret()
@@ -271,6 +503,12 @@ func (k *Kubernetes) initPodSpec(projectService ProjectService) v1.PodSpec {
 			},
 		}
 	}
+	// @step reference the project registry credentials secret, if configured
+	if k.registryAuthSecret != "" && k.registryAuthSecret != pullSecret {
+		pod.ImagePullSecrets = append(pod.ImagePullSecrets, v1.LocalObjectReference{
+			Name: k.registryAuthSecret,
+		})
+	}
 	if serviceAccount != "" {
 		pod.ServiceAccountName = serviceAccount
 	}
@@ -318,24 +556,35 @@ func (k *Kubernetes) initPodSpecWithConfigMap(projectService ProjectService) v1.
 		key, err := k.getConfigMapKeyFromMeta(value.Source)
 		if err != nil {
 			// config is most likely defined as external
-			log.WarnfWithFields(log.Fields{
+			k.warn(projectService.Name, "configs", log.Fields{
 				"project-service": projectService.Name,
 				"config":          value.Source,
-			}, "Cannot parse config: %s", err.Error())
+			}, fmt.Sprintf("Cannot parse config: %s", err.Error()))
 
 			continue
 		}
 
-		volSource.Items = []v1.KeyToPath{{
+		// @step K8s can't set config map items ownership
+		if value.UID != "" || value.GID != "" {
+			k.warn(projectService.Name, "configs", log.Fields{
+				"project-service": projectService.Name,
+				"config":          value.Source,
+			}, "Ignoring `uid` and `gid` fields on compose project service config")
+		}
+
+		item := v1.KeyToPath{
 			Key:  key,
 			Path: subPath,
-		}}
+		}
 
 		if value.Mode != nil {
 			tmpMode := int32(*value.Mode)
+			item.Mode = &tmpMode
 			volSource.DefaultMode = &tmpMode
 		}
 
+		volSource.Items = []v1.KeyToPath{item}
+
 		cmVol := v1.Volume{
 			Name:         cmVolName,
 			VolumeSource: v1.VolumeSource{ConfigMap: &volSource},
@@ -355,7 +604,7 @@ func (k *Kubernetes) initPodSpecWithConfigMap(projectService ProjectService) v1.
 	pod.Containers = []v1.Container{
 		{
 			Name:         projectService.Name,
-			Image:        projectService.Image,
+			Image:        k.imageName(projectService.Image),
 			VolumeMounts: volumeMounts,
 		},
 	}
@@ -373,7 +622,7 @@ func (k *Kubernetes) initSvc(projectService ProjectService) *v1.Service {
 			APIVersion: "v1",
 		},
 		ObjectMeta: meta.ObjectMeta{
-			Name:   rfc1123label(projectService.Name),
+			Name:   k.labelName(projectService.Name),
 			Labels: configLabels(projectService.Name),
 		},
 		Spec: v1.ServiceSpec{
@@ -391,7 +640,7 @@ func (k *Kubernetes) initConfigMap(projectService ProjectService, configMapName
 			APIVersion: "v1",
 		},
 		ObjectMeta: meta.ObjectMeta{
-			Name:   rfc1123dns(configMapName),
+			Name:   k.dnsName(configMapName),
 			Labels: configLabels(projectService.Name),
 		},
 		Data: data,
@@ -420,7 +669,7 @@ func (k *Kubernetes) initConfigMapFromFileOrDir(projectService ProjectService, c
 		if err != nil {
 			return nil, fmt.Errorf("Couldn't initiate ConfigMap from file: %s", err)
 		}
-		configMap.Name = rfc1123dns(configMapName) // always override name with passed value
+		configMap.Name = k.dnsName(configMapName) // always override name with passed value
 		configMap.Annotations = map[string]string{
 			"use-subpath": "true",
 		}
@@ -472,10 +721,18 @@ func (k *Kubernetes) initConfigMapFromFile(projectService ProjectService, fileNa
 	dataMap := make(map[string]string)
 	dataMap[filepath.Base(fileName)] = content
 
+	// @step pick the first matching config in name order so that rendered output is reproducible
+	names := make([]string, 0, len(k.Project.Configs))
+	for name := range k.Project.Configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	configMapName := ""
-	for key, tmpConfig := range k.Project.Configs {
-		if tmpConfig.File == fileName {
-			configMapName = key
+	for _, name := range names {
+		if k.Project.Configs[name].File == fileName {
+			configMapName = name
+			break
 		}
 	}
 
@@ -514,7 +771,7 @@ func (k *Kubernetes) initDeployment(projectService ProjectService) *v1apps.Deplo
 			},
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: meta.ObjectMeta{
-					Annotations: configAnnotations(projectService.Labels, projectService.podAnnotations()),
+					Annotations: configAnnotations(projectService.labelAnnotations(), projectService.podAnnotations()),
 					Labels:      configLabels(projectService.Name),
 				},
 				Spec: podSpec,
@@ -537,6 +794,27 @@ func (k *Kubernetes) initDeployment(projectService ProjectService) *v1apps.Deplo
 		}, "Set deployment rolling update")
 	}
 
+	// @step surface rollback config as K8s has no configurable rollback behaviour
+	if rollback := projectService.rollbackConfigAnnotations(); len(rollback) > 0 {
+		dc.ObjectMeta.Annotations = configAnnotations(dc.ObjectMeta.Annotations, rollback)
+	}
+
+	// @step surface update failure action as K8s doesn't act on failed rollouts on its own.
+	// A rollout exceeding progress deadline is only reported as failed, K8s never pauses or rolls it back.
+	if action := projectService.updateFailureAction(); action != "" {
+		if dc.ObjectMeta.Annotations == nil {
+			dc.ObjectMeta.Annotations = map[string]string{}
+		}
+		dc.ObjectMeta.Annotations[UpdateFailureActionAnnotation] = action
+
+		if action != "continue" {
+			k.warn(projectService.Name, "deploy.update_config.failure_action", log.Fields{
+				"project-service": projectService.Name,
+				"failure-action":  action,
+			}, "K8s only reports failed rollouts once progress deadline is exceeded. Failure action must be handled outside of the cluster, e.g. with `kubectl rollout undo`.")
+		}
+	}
+
 	return dc
 }
 
@@ -554,8 +832,12 @@ func (k *Kubernetes) initDaemonSet(projectService ProjectService) *v1apps.Daemon
 		},
 		Spec: v1apps.DaemonSetSpec{
 			Template: v1.PodTemplateSpec{
+				ObjectMeta: meta.ObjectMeta{
+					Annotations: configAnnotations(projectService.labelAnnotations(), projectService.podAnnotations()),
+				},
 				Spec: k.initPodSpec(projectService),
 			},
+			UpdateStrategy: projectService.daemonSetUpdateStrategy(),
 		},
 	}
 	return ds
@@ -588,7 +870,7 @@ func (k *Kubernetes) initStatefulSet(projectService ProjectService) *v1apps.Stat
 			},
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: meta.ObjectMeta{
-					Annotations: configAnnotations(projectService.Labels, projectService.podAnnotations()),
+					Annotations: configAnnotations(projectService.labelAnnotations(), projectService.podAnnotations()),
 					Labels:      configLabels(projectService.Name),
 				},
 				Spec: podSpec,
@@ -598,6 +880,7 @@ func (k *Kubernetes) initStatefulSet(projectService ProjectService) *v1apps.Stat
 				Type:          v1apps.RollingUpdateStatefulSetStrategyType,
 				RollingUpdate: &v1apps.RollingUpdateStatefulSetStrategy{},
 			},
+			PersistentVolumeClaimRetentionPolicy: projectService.pvcRetentionPolicy(),
 		},
 	}
 
@@ -606,7 +889,7 @@ func (k *Kubernetes) initStatefulSet(projectService ProjectService) *v1apps.Stat
 
 // initJob initialises a new Kubernetes Job
 func (k *Kubernetes) initJob(projectService ProjectService, replicas int) *v1batch.Job {
-	repl := int32(replicas)
+	completions, parallelism := projectService.jobCounts(replicas)
 
 	var podSpec v1.PodSpec
 	if len(projectService.Configs) > 0 {
@@ -615,6 +898,12 @@ func (k *Kubernetes) initJob(projectService ProjectService, replicas int) *v1bat
 		podSpec = k.initPodSpec(projectService)
 	}
 
+	// @step jobs only support OnFailure and Never restart policies.
+	// Invalid policies are reported when the pod template gets updated.
+	if restartPolicy, err := k.jobRestartPolicy(projectService); err == nil {
+		podSpec.RestartPolicy = restartPolicy
+	}
+
 	j := &v1batch.Job{
 		TypeMeta: meta.TypeMeta{
 			Kind:       "Job",
@@ -625,14 +914,15 @@ func (k *Kubernetes) initJob(projectService ProjectService, replicas int) *v1bat
 			Labels: configAllLabels(projectService),
 		},
 		Spec: v1batch.JobSpec{
-			Parallelism: &repl,
-			Completions: &repl,
+			Parallelism:    &parallelism,
+			Completions:    &completions,
+			CompletionMode: projectService.jobCompletionMode(),
 			Selector: &meta.LabelSelector{
 				MatchLabels: configLabels(projectService.Name),
 			},
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: meta.ObjectMeta{
-					Annotations: configAnnotations(projectService.Labels, projectService.podAnnotations()),
+					Annotations: configAnnotations(projectService.labelAnnotations(), projectService.podAnnotations()),
 					Labels:      configLabels(projectService.Name),
 				},
 				Spec: podSpec,
@@ -643,6 +933,46 @@ func (k *Kubernetes) initJob(projectService ProjectService, replicas int) *v1bat
 	return j
 }
 
+// jobCompletions returns number of job completions for the project service.
+// K8s has no equivalent of per node compose `global-job` services, which run as a single completion job instead.
+func (k *Kubernetes) jobCompletions(projectService ProjectService) int {
+	if projectService.Deploy != nil && projectService.Deploy.Mode == "global-job" {
+		k.warn(projectService.Name, "deploy.mode", log.Fields{
+			"project-service": projectService.Name,
+		}, "Per node `global-job` services aren't supported by K8s and will run as a single completion Job")
+
+		return 1
+	}
+
+	return int(projectService.replicas())
+}
+
+// jobRestartPolicy returns project service restart policy applicable to jobs.
+// `Always` isn't allowed for jobs and gets coerced to `OnFailure`, with a warning
+// only when it was set explicitly in the compose service, as it's the default otherwise.
+func (k *Kubernetes) jobRestartPolicy(projectService ProjectService) (v1.RestartPolicy, error) {
+	restartPolicy, err := projectService.restartPolicy()
+	if err != nil {
+		return "", err
+	}
+
+	if restartPolicy != v1.RestartPolicyAlways {
+		return restartPolicy, nil
+	}
+
+	explicit := projectService.Restart != "" ||
+		(projectService.Deploy != nil && projectService.Deploy.RestartPolicy != nil)
+
+	if explicit {
+		k.warn(projectService.Name, "restart", log.Fields{
+			"project-service": projectService.Name,
+			"restart-policy":  restartPolicy,
+		}, "Restart policy `Always` isn't supported by jobs and will be replaced with `OnFailure`")
+	}
+
+	return v1.RestartPolicyOnFailure, nil
+}
+
 // initIngress initialises ingress object
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L446
 func (k *Kubernetes) initIngress(projectService ProjectService, port int32) *networkingv1.Ingress {
@@ -658,7 +988,7 @@ func (k *Kubernetes) initIngress(projectService ProjectService, port int32) *net
 			APIVersion: "networking.k8s.io/v1",
 		},
 		ObjectMeta: meta.ObjectMeta{
-			Name:        projectService.Name,
+			Name:        projectService.ingressName(),
 			Labels:      configLabels(projectService.Name),
 			Annotations: projectService.ingressAnnotations(),
 		},
@@ -697,12 +1027,64 @@ func (k *Kubernetes) initIngress(projectService ProjectService, port int32) *net
 	return ingress
 }
 
+// splitIngressPerHost splits ingress into one ingress per host when host specific annotations are configured,
+// as annotations apply to the whole Ingress object. Each ingress gets common annotations merged with its host ones.
+func (k *Kubernetes) splitIngressPerHost(projectService ProjectService, ingress *networkingv1.Ingress) []*networkingv1.Ingress {
+	hostAnnotations := projectService.ingressHostAnnotations()
+	if len(hostAnnotations) == 0 || ingress.Spec.DefaultBackend != nil {
+		return []*networkingv1.Ingress{ingress}
+	}
+
+	// @step group rules by host, as a host with multiple paths yields multiple rules
+	var hosts []string
+	rules := map[string][]networkingv1.IngressRule{}
+	for _, rule := range ingress.Spec.Rules {
+		if _, ok := rules[rule.Host]; !ok {
+			hosts = append(hosts, rule.Host)
+		}
+		rules[rule.Host] = append(rules[rule.Host], rule)
+	}
+
+	for host := range hostAnnotations {
+		if _, ok := rules[host]; !ok {
+			k.warn(projectService.Name, "ingressHostAnnotations", log.Fields{
+				"project-service": projectService.Name,
+				"host":            host,
+			}, "Ingress annotations defined for a host which isn't exposed. Skipping ...")
+		}
+	}
+
+	ingresses := []*networkingv1.Ingress{}
+	for _, host := range hosts {
+		hostIngress := ingress.DeepCopy()
+		hostIngress.Name = k.dnsName(fmt.Sprintf("%s-%s", ingress.Name, host))
+		hostIngress.Spec.Rules = rules[host]
+
+		annotations := map[string]string{}
+		for k, v := range ingress.Annotations {
+			annotations[k] = v
+		}
+		for k, v := range hostAnnotations[host] {
+			annotations[k] = v
+		}
+		hostIngress.Annotations = annotations
+
+		for i := range hostIngress.Spec.TLS {
+			hostIngress.Spec.TLS[i].Hosts = []string{host}
+		}
+
+		ingresses = append(ingresses, hostIngress)
+	}
+
+	return ingresses
+}
+
 // initHpa initialises horizontal pod autoscaler for a project service
 func (k *Kubernetes) initHpa(projectService ProjectService, target runtime.Object) *autoscalingv2beta2.HorizontalPodAutoscaler {
 	t := reflect.ValueOf(target).Elem()
 	typeMeta := t.FieldByName("TypeMeta").Interface().(meta.TypeMeta)
 	if !contains([]string{"Deployment", "StatefulSet"}, typeMeta.Kind) {
-		log.WarnWithFields(log.Fields{
+		k.warn(projectService.Name, "autoscale", log.Fields{
 			"project-service": projectService.Name,
 			"kind":            typeMeta.Kind,
 		}, "Unsupported target kind for Horizontal Pod Autoscaler. Skipping ...")
@@ -727,7 +1109,7 @@ func (k *Kubernetes) initHpa(projectService ProjectService, target runtime.Objec
 
 	// max replicas should be greater than min replicas!
 	if maxRepl > 0 && maxRepl <= replicas {
-		log.WarnWithFields(log.Fields{
+		k.warn(projectService.Name, "autoscale", log.Fields{
 			"project-service":        projectService.Name,
 			"replicas":               replicas,
 			"autoscale-max-replicas": maxRepl,
@@ -772,7 +1154,7 @@ func (k *Kubernetes) initHpa(projectService ProjectService, target runtime.Objec
 		ObjectMeta: meta.ObjectMeta{
 			Name:        projectService.Name,
 			Labels:      configLabels(projectService.Name),
-			Annotations: configAnnotations(projectService.Labels),
+			Annotations: configAnnotations(projectService.labelAnnotations()),
 		},
 		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
 			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
@@ -791,12 +1173,13 @@ func (k *Kubernetes) initHpa(projectService ProjectService, target runtime.Objec
 }
 
 // initServiceAccount initialises Service Account for a project service
-// It only creates the ServiceAccount spec for accounts with name other than `default`
+// It only creates the ServiceAccount spec for accounts with name other than `default`,
+// unless the account is managed externally and only referenced by the workload
 func (k *Kubernetes) initServiceAccount(projectService ProjectService) *v1.ServiceAccount {
 	automountSAToken := false
 	saname := projectService.serviceAccountName()
 
-	if saname != "default" && len(strings.TrimSpace(saname)) > 0 {
+	if saname != "default" && len(strings.TrimSpace(saname)) > 0 && projectService.createServiceAccount() {
 		return &v1.ServiceAccount{
 			TypeMeta: meta.TypeMeta{
 				Kind:       "ServiceAccount",
@@ -805,7 +1188,7 @@ func (k *Kubernetes) initServiceAccount(projectService ProjectService) *v1.Servi
 			ObjectMeta: meta.ObjectMeta{
 				Name:        saname,
 				Labels:      configLabels(projectService.Name),
-				Annotations: configAnnotations(projectService.Labels),
+				Annotations: configAnnotations(projectService.labelAnnotations()),
 			},
 			AutomountServiceAccountToken: &automountSAToken,
 		}
@@ -814,13 +1197,91 @@ func (k *Kubernetes) initServiceAccount(projectService ProjectService) *v1.Servi
 	return nil
 }
 
+// initRBAC initialises RBAC Role and RoleBinding granting access rules to the project service Service Account
+// It only creates the RBAC specs when access rules have been specified
+func (k *Kubernetes) initRBAC(projectService ProjectService, sa *v1.ServiceAccount) (*rbacv1.Role, *rbacv1.RoleBinding) {
+	rules := projectService.rbacRules()
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	policyRules := []rbacv1.PolicyRule{}
+	for _, r := range rules {
+		apiGroups := r.APIGroups
+		if len(apiGroups) == 0 {
+			// core API group
+			apiGroups = []string{""}
+		}
+
+		policyRules = append(policyRules, rbacv1.PolicyRule{
+			APIGroups: apiGroups,
+			Resources: r.Resources,
+			Verbs:     r.Verbs,
+		})
+	}
+
+	role := &rbacv1.Role{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "Role",
+			APIVersion: "rbac.authorization.k8s.io/v1",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:        sa.Name,
+			Labels:      configLabels(projectService.Name),
+			Annotations: configAnnotations(projectService.labelAnnotations()),
+		},
+		Rules: policyRules,
+	}
+
+	binding := &rbacv1.RoleBinding{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "RoleBinding",
+			APIVersion: "rbac.authorization.k8s.io/v1",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:        sa.Name,
+			Labels:      configLabels(projectService.Name),
+			Annotations: configAnnotations(projectService.labelAnnotations()),
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind: rbacv1.ServiceAccountKind,
+				Name: sa.Name,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     role.Name,
+		},
+	}
+
+	return role, binding
+}
+
 // createSecrets create secrets
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L502
 func (k *Kubernetes) createSecrets() ([]*v1.Secret, error) {
 	var objects []*v1.Secret
-	for name, secretConfig := range k.Project.Secrets {
-		if secretConfig.File != "" {
-			dataString, err := getContentFromFile(secretConfig.File)
+
+	// @step iterate over secrets in name order so that rendered output is reproducible
+	names := make([]string, 0, len(k.Project.Secrets))
+	for name := range k.Project.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		secretConfig := k.Project.Secrets[name]
+
+		if cert, key, ok := secretTLSFiles(secretConfig); ok {
+			secret, err := k.createTLSSecret(name, cert, key)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, secret)
+		} else if secretConfig.File != "" {
+			data, err := getSecretDataFromFileOrDir(k.secretDataKey(name), secretConfig.File)
 			if err != nil {
 				log.ErrorWithFields(log.Fields{
 					"file": secretConfig.File,
@@ -828,20 +1289,22 @@ func (k *Kubernetes) createSecrets() ([]*v1.Secret, error) {
 
 				return nil, err
 			}
-			data := []byte(dataString)
 			secret := &v1.Secret{
 				TypeMeta: meta.TypeMeta{
 					Kind:       "Secret",
 					APIVersion: "v1",
 				},
 				ObjectMeta: meta.ObjectMeta{
-					Name:   name,
+					Name:   k.dnsName(name),
 					Labels: configLabels(name),
 				},
 				Type: v1.SecretTypeOpaque,
-				Data: map[string][]byte{name: data},
+				Data: data,
 			}
 			objects = append(objects, secret)
+		} else if store, _ := k.externalSecretRef(name, secretConfig); store != "" {
+			// @step external secret is fetched by External Secrets Operator, see createExternalSecrets
+			continue
 		} else {
 			log.WarnWithFields(log.Fields{
 				"secret-name": name,
@@ -854,6 +1317,255 @@ func (k *Kubernetes) createSecrets() ([]*v1.Secret, error) {
 	return objects, nil
 }
 
+// externalSecretRef returns External Secrets Operator SecretStore name and remote key for the external project secret.
+// Store defaults to the one configured via options, and remote key to the external secret name.
+func (k *Kubernetes) externalSecretRef(name string, secretConfig composego.SecretConfig) (store string, remoteKey string) {
+	if !secretConfig.External.External {
+		return "", ""
+	}
+
+	store, remoteKey = secretExternalRef(secretConfig)
+	if store == "" {
+		store = k.Opt.ExternalSecretStore
+	}
+
+	if remoteKey == "" {
+		remoteKey = name
+		if secretConfig.External.Name != "" {
+			remoteKey = secretConfig.External.Name
+		}
+	}
+
+	return store, remoteKey
+}
+
+// createExternalSecrets creates External Secrets Operator ExternalSecret objects for external project secrets
+// with a SecretStore configured. The resulting K8s secret is named after the project secret.
+func (k *Kubernetes) createExternalSecrets() []runtime.Object {
+	var objects []runtime.Object
+
+	names := make([]string, 0, len(k.Project.Secrets))
+	for name := range k.Project.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		store, remoteKey := k.externalSecretRef(name, k.Project.Secrets[name])
+		if store == "" {
+			continue
+		}
+
+		es := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": ExternalSecretAPIVersion,
+				"kind":       "ExternalSecret",
+				"metadata": map[string]interface{}{
+					"name": k.dnsName(name),
+				},
+				"spec": map[string]interface{}{
+					"secretStoreRef": map[string]interface{}{
+						"name": store,
+						"kind": "SecretStore",
+					},
+					"target": map[string]interface{}{
+						"name": k.dnsName(name),
+					},
+					"data": []interface{}{
+						map[string]interface{}{
+							"secretKey": k.secretDataKey(name),
+							"remoteRef": map[string]interface{}{
+								"key": remoteKey,
+							},
+						},
+					},
+				},
+			},
+		}
+		es.SetLabels(configLabels(name))
+
+		log.DebugWithFields(log.Fields{
+			"secret-name": name,
+			"store":       store,
+		}, "External secret will be fetched by External Secrets Operator")
+
+		objects = append(objects, es)
+	}
+
+	return objects
+}
+
+// secretDataKey returns the data key under which a single file project secret content is stored.
+// That's the secret file base name, so that the secret mounted into a container keeps the original file name,
+// or the secret name itself when legacy secret keys are requested or secret isn't sourced from a single file.
+func (k *Kubernetes) secretDataKey(name string) string {
+	if k.Opt.LegacySecretKeys || k.Project == nil {
+		return name
+	}
+
+	secretConfig, ok := k.Project.Secrets[name]
+	if !ok || secretConfig.File == "" || k.multiKeySecret(name) {
+		return name
+	}
+
+	return filepath.Base(secretConfig.File)
+}
+
+// secretItems returns keys of the project secret projected into a mounted secret volume, with single file
+// secret content projected at the item path. Secrets holding multiple keys are projected whole,
+// i.e. both certificate and key files of TLS secrets, and every file of a directory secret.
+func (k *Kubernetes) secretItems(name, itemPath string) []v1.KeyToPath {
+	if k.multiKeySecret(name) {
+		return nil
+	}
+
+	return []v1.KeyToPath{{
+		Key:  k.secretDataKey(name),
+		Path: itemPath,
+	}}
+}
+
+// multiKeySecret tells whether the project secret holds multiple data keys, i.e. it's a TLS secret,
+// or it's sourced from a directory and stores a data key per file
+func (k *Kubernetes) multiKeySecret(name string) bool {
+	if k.Project == nil {
+		return false
+	}
+
+	secretConfig, ok := k.Project.Secrets[name]
+	if !ok {
+		return false
+	}
+
+	if _, _, tls := secretTLSFiles(secretConfig); tls {
+		return true
+	}
+
+	if secretConfig.File == "" {
+		return false
+	}
+
+	fi, err := os.Stat(secretConfig.File)
+	return err == nil && fi.IsDir()
+}
+
+// registryAuth returns private container registry credentials configured via the project extension, nil if not configured.
+// NOTE: credentials must never be logged!
+func (k *Kubernetes) registryAuth() (*RegistryAuth, error) {
+	ext, ok := k.Project.Extensions[RegistryAuthExtensionKey]
+	if !ok {
+		return nil, nil
+	}
+
+	data, err := yaml.Marshal(ext)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &RegistryAuth{}
+	if err := yaml.Unmarshal(data, auth); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s extension", RegistryAuthExtensionKey)
+	}
+
+	if auth.Server == "" || auth.Username == "" || auth.Password == "" {
+		return nil, fmt.Errorf("%s extension requires server, username and password", RegistryAuthExtensionKey)
+	}
+
+	if auth.Name == "" {
+		auth.Name = DefaultRegistryAuthSecretName
+	}
+
+	return auth, nil
+}
+
+// createRegistryAuthSecret creates a `kubernetes.io/dockerconfigjson` image pull secret
+// from the project registry credentials. It returns nil when credentials aren't configured.
+func (k *Kubernetes) createRegistryAuthSecret() (*v1.Secret, error) {
+	auth, err := k.registryAuth()
+	if err != nil || auth == nil {
+		return nil, err
+	}
+
+	type dockerConfigEntry struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Email    string `json:"email,omitempty"`
+		Auth     string `json:"auth"`
+	}
+
+	dockerConfig, err := json.Marshal(map[string]map[string]dockerConfigEntry{
+		"auths": {
+			auth.Server: {
+				Username: auth.Username,
+				Password: auth.Password,
+				Email:    auth.Email,
+				Auth:     base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password)),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.Secret{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:   auth.Name,
+			Labels: configLabels(auth.Name),
+		},
+		Type: v1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			v1.DockerConfigJsonKey: dockerConfig,
+		},
+	}, nil
+}
+
+// createTLSSecret creates a `kubernetes.io/tls` secret from certificate and key files.
+// Relative file paths are resolved against the compose file directory.
+func (k *Kubernetes) createTLSSecret(name, certFile, keyFile string) (*v1.Secret, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("TLS secret %s requires both `%s` and `%s` file paths", name, SecretTLSCertExtensionKey, SecretTLSKeyExtensionKey)
+	}
+
+	data := map[string][]byte{}
+	for secretKey, file := range map[string]string{v1.TLSCertKey: certFile, v1.TLSPrivateKeyKey: keyFile} {
+		if !filepath.IsAbs(file) {
+			dir, err := k.composeFileDir()
+			if err != nil {
+				return nil, err
+			}
+			file = filepath.Join(dir, file)
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			log.ErrorWithFields(log.Fields{
+				"secret-name": name,
+				"file":        file,
+			}, "Unable to read TLS secret file")
+
+			return nil, errors.Wrapf(err, "TLS secret %s", name)
+		}
+		data[secretKey] = content
+	}
+
+	return &v1.Secret{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:   k.dnsName(name),
+			Labels: configLabels(name),
+		},
+		Type: v1.SecretTypeTLS,
+		Data: data,
+	}, nil
+}
+
 // createPVC initializes PersistentVolumeClaim
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L534
 func (k *Kubernetes) createPVC(volume Volumes) (*v1.PersistentVolumeClaim, error) {
@@ -870,7 +1582,7 @@ func (k *Kubernetes) createPVC(volume Volumes) (*v1.PersistentVolumeClaim, error
 			APIVersion: "v1",
 		},
 		ObjectMeta: meta.ObjectMeta{
-			Name:   volume.VolumeName,
+			Name:   k.dnsName(volume.VolumeName),
 			Labels: configLabels(volume.VolumeName),
 		},
 		Spec: v1.PersistentVolumeClaimSpec{
@@ -917,6 +1629,7 @@ func (k *Kubernetes) configPorts(projectService ProjectService) []v1.ContainerPo
 		}
 
 		ports = append(ports, v1.ContainerPort{
+			Name:          k.containerPortName(projectService, port),
 			ContainerPort: int32(port.Target),
 			Protocol:      v1.Protocol(protocol),
 			HostIP:        port.HostIP,
@@ -928,6 +1641,26 @@ func (k *Kubernetes) configPorts(projectService ProjectService) []v1.ContainerPo
 	return ports
 }
 
+// containerPortName returns a valid container port name for the given port, or empty string when
+// the port isn't named. Invalid port names are ignored with a warning.
+func (k *Kubernetes) containerPortName(projectService ProjectService, port composego.ServicePortConfig) string {
+	name := portName(port)
+	if name == "" {
+		return ""
+	}
+
+	if errs := validation.IsValidPortName(name); len(errs) > 0 {
+		k.warn(projectService.Name, "ports", log.Fields{
+			"project-service": projectService.Name,
+			"port":            port.Target,
+			"name":            name,
+		}, fmt.Sprintf("Invalid container port name ignored: %s", strings.Join(errs, ", ")))
+		return ""
+	}
+
+	return name
+}
+
 // configServicePorts configure the container service ports.
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L602
 func (k *Kubernetes) configServicePorts(serviceType config.ServiceType, projectService ProjectService) []v1.ServicePort {
@@ -944,12 +1677,17 @@ func (k *Kubernetes) configServicePorts(serviceType config.ServiceType, projectS
 		targetPort.IntVal = int32(port.Target)
 		targetPort.StrVal = strconv.Itoa(int(port.Target))
 
+		// @step target named container port by its name (invalid names are reported when configuring container ports)
+		if portName := portName(port); portName != "" && len(validation.IsValidPortName(portName)) == 0 {
+			targetPort = intstr.FromString(portName)
+		}
+
 		// @step define port name depending on whether it was seen before
 		name := strconv.Itoa(int(port.Published))
 		if _, ok := seenPorts[int(port.Published)]; ok {
 			// https://github.com/kubernetes/kubernetes/issues/2995
 			if config.ServiceTypesEqual(serviceType, config.LoadBalancerService) {
-				log.WarnWithFields(log.Fields{
+				k.warn(projectService.Name, "ports", log.Fields{
 					"project-service": projectService.Name,
 					"port":            port.Published,
 				}, "LoadBalancer service type cannot use TCP and UDP for the same port")
@@ -1042,13 +1780,18 @@ func (k *Kubernetes) configSecretVolumes(projectService ProjectService) ([]v1.Vo
 
 	if len(projectService.Secrets) > 0 {
 		for _, secretConfig := range projectService.Secrets {
+			// @step secrets delivered as environment variables are not mounted, unless they hold multiple keys
+			if secretEnvName(secretConfig) != "" && !k.multiKeySecret(secretConfig.Source) {
+				continue
+			}
+
 			if secretConfig.UID != "" {
-				log.WarnWithFields(log.Fields{
+				k.warn(projectService.Name, "secrets", log.Fields{
 					"project-service": projectService.Name,
 				}, "Ignoring `uid` field on compose project service secret")
 			}
 			if secretConfig.GID != "" {
-				log.WarnWithFields(log.Fields{
+				k.warn(projectService.Name, "secrets", log.Fields{
 					"project-service": projectService.Name,
 				}, "Ignoring `gid` field on compose project service secret")
 			}
@@ -1060,8 +1803,8 @@ func (k *Kubernetes) configSecretVolumes(projectService ProjectService) ([]v1.Vo
 			if secretConfig.Target == "" {
 				// the secret path (mountPath) should be inside the default directory /run/secrets
 				mountPath = "/run/secrets/" + secretConfig.Source
-				// the itemPath should be the source itself
-				itemPath = secretConfig.Source
+				// the itemPath should be the secret data key, i.e. the secret file base name or the source itself
+				itemPath = k.secretDataKey(secretConfig.Source)
 			} else {
 				// long-syntax, get the last part of the path and consider it the filename
 				pathSplitted := strings.Split(secretConfig.Target, "/")
@@ -1087,11 +1830,8 @@ func (k *Kubernetes) configSecretVolumes(projectService ProjectService) ([]v1.Vo
 
 			volSource := v1.VolumeSource{
 				Secret: &v1.SecretVolumeSource{
-					SecretName: secretConfig.Source,
-					Items: []v1.KeyToPath{{
-						Key:  secretConfig.Source,
-						Path: itemPath,
-					}},
+					SecretName: k.dnsName(secretConfig.Source),
+					Items:      k.secretItems(secretConfig.Source, itemPath),
 				},
 			}
 
@@ -1101,7 +1841,7 @@ func (k *Kubernetes) configSecretVolumes(projectService ProjectService) ([]v1.Vo
 			}
 
 			vol := v1.Volume{
-				Name:         secretConfig.Source,
+				Name:         k.labelName(secretConfig.Source),
 				VolumeSource: volSource,
 			}
 			volumes = append(volumes, vol)
@@ -1117,6 +1857,85 @@ func (k *Kubernetes) configSecretVolumes(projectService ProjectService) ([]v1.Vo
 	return volumeMounts, volumes
 }
 
+// configBoundTokenVolumes configures projected volumes with bound Service Account tokens.
+// Each token is projected into a `token` file in the configured mount path.
+func (k *Kubernetes) configBoundTokenVolumes(projectService ProjectService) ([]v1.VolumeMount, []v1.Volume) {
+	var volumeMounts []v1.VolumeMount
+	var volumes []v1.Volume
+
+	for i, token := range projectService.boundTokens() {
+		name := fmt.Sprintf("bound-token-%d", i)
+
+		tokenProjection := &v1.ServiceAccountTokenProjection{
+			Audience: token.Audience,
+			Path:     "token",
+		}
+
+		if token.ExpirationSeconds > 0 {
+			expiration := token.ExpirationSeconds
+			tokenProjection.ExpirationSeconds = &expiration
+		}
+
+		volumes = append(volumes, v1.Volume{
+			Name: name,
+			VolumeSource: v1.VolumeSource{
+				Projected: &v1.ProjectedVolumeSource{
+					Sources: []v1.VolumeProjection{
+						{ServiceAccountToken: tokenProjection},
+					},
+				},
+			},
+		})
+
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      name,
+			MountPath: token.MountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	return volumeMounts, volumes
+}
+
+// configDeviceVolumes maps project service devices onto host path volumes mounted at the device container path
+func (k *Kubernetes) configDeviceVolumes(projectService ProjectService) ([]v1.VolumeMount, []v1.Volume) {
+	var volumeMounts []v1.VolumeMount
+	var volumes []v1.Volume
+
+	for i, device := range projectService.Devices {
+		host, container := parseDevice(device)
+		if host == "" {
+			continue
+		}
+
+		name := fmt.Sprintf("device-%d", i)
+
+		volumes = append(volumes, v1.Volume{
+			Name: name,
+			VolumeSource: v1.VolumeSource{
+				HostPath: &v1.HostPathVolumeSource{
+					Path: host,
+					Type: deviceHostPathType(host),
+				},
+			},
+		})
+
+		volumeMounts = append(volumeMounts, v1.VolumeMount{
+			Name:      name,
+			MountPath: container,
+		})
+	}
+
+	// @step host devices can only be accessed from privileged containers
+	if len(volumes) > 0 && !projectService.Privileged {
+		k.warn(projectService.Name, "devices", log.Fields{
+			"project-service": projectService.Name,
+		}, "Host devices are only accessible from privileged containers, set `privileged: true` or grant access explicitly")
+	}
+
+	return volumeMounts, volumes
+}
+
 // configVolumes configure the container volumes.
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L774
 func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMount, []v1.Volume, []*v1.PersistentVolumeClaim, []*v1.ConfigMap, error) {
@@ -1140,6 +1959,16 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 	volumeMounts = append(volumeMounts, secretsVolumeMounts...)
 	volumes = append(volumes, secretsVolumes...)
 
+	// @step config projected service account token volumes if present
+	tokenVolumeMounts, tokenVolumes := k.configBoundTokenVolumes(projectService)
+	volumeMounts = append(volumeMounts, tokenVolumeMounts...)
+	volumes = append(volumes, tokenVolumes...)
+
+	// @step config host device volumes if present
+	deviceVolumeMounts, deviceVolumes := k.configDeviceVolumes(projectService)
+	volumeMounts = append(volumeMounts, deviceVolumeMounts...)
+	volumes = append(volumes, deviceVolumes...)
+
 	var count int
 	// @step iterate over project service volumes
 	projectServiceVolumes, err := projectService.volumes(k.Project)
@@ -1150,6 +1979,9 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 
 		// check if ro/rw mode is defined, default rw
 		readonly := len(volume.Mode) > 0 && volume.Mode == "ro"
+		if volume.ReadOnly != nil {
+			readonly = *volume.ReadOnly
+		}
 
 		if volume.VolumeName == "" {
 			if useEmptyVolumes {
@@ -1187,7 +2019,7 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 				"project-service": projectService.Name,
 			}, "Use HostPath volume")
 
-			source, err := k.configHostPathVolumeSource(volume.Host)
+			source, err := k.configHostPathVolumeSource(volume.Host, volume.HostPathType)
 			if err != nil {
 				log.Error("Couldn't create HostPath volume source")
 				return nil, nil, nil, nil, err
@@ -1230,6 +2062,28 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 			}
 
 		}
+
+		// @step set mount propagation if configured
+		if volume.Propagation != "" {
+			propagation := v1.MountPropagationMode(volume.Propagation)
+			volMount.MountPropagation = &propagation
+
+			if propagation == v1.MountPropagationBidirectional && !projectService.Privileged {
+				k.warn(projectService.Name, "volumes", log.Fields{
+					"project-service": projectService.Name,
+					"volume":          volumeName,
+				}, "Bidirectional mount propagation is only allowed in privileged containers")
+			}
+		}
+
+		// @step mount volume sub path if configured
+		if volume.SubPathExpr != "" {
+			volMount.SubPath = ""
+			volMount.SubPathExpr = volume.SubPathExpr
+		} else if volume.SubPath != "" {
+			volMount.SubPath = volume.SubPath
+		}
+
 		volumeMounts = append(volumeMounts, volMount)
 
 		// @step create a new volume object using the volsource and add to list
@@ -1240,7 +2094,7 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 		volumes = append(volumes, vol)
 
 		if len(volume.Host) > 0 && (!useHostPath && !useConfigMap) {
-			log.WarnWithFields(log.Fields{
+			k.warn(projectService.Name, "volumes", log.Fields{
 				"project-service": projectService.Name,
 				"host":            volume.Host,
 			}, "Volume mount on the host isn't supported. Ignoring path on the host")
@@ -1250,6 +2104,49 @@ func (k *Kubernetes) configVolumes(projectService ProjectService) ([]v1.VolumeMo
 	return volumeMounts, volumes, PVCs, cms, nil
 }
 
+// configSubPathExprEnvs appends env vars referenced by volume mounts sub path expressions which aren't defined
+// by the project service. Well known pod fields, e.g. `$(POD_NAME)`, are injected as pod field references.
+func (k *Kubernetes) configSubPathExprEnvs(projectService ProjectService, volumeMounts []v1.VolumeMount, envs []v1.EnvVar) []v1.EnvVar {
+	defined := map[string]bool{}
+	for _, e := range envs {
+		defined[e.Name] = true
+	}
+
+	re := regexp.MustCompile(`\$\(([A-Za-z_][A-Za-z0-9_]*)\)`)
+
+	for _, vm := range volumeMounts {
+		for _, match := range re.FindAllStringSubmatch(vm.SubPathExpr, -1) {
+			name := match[1]
+			if defined[name] {
+				continue
+			}
+
+			fieldPath, ok := SubPathExprPodFields[name]
+			if !ok {
+				k.warn(projectService.Name, "volumes", log.Fields{
+					"project-service": projectService.Name,
+					"volume":          vm.Name,
+					"env-var":         name,
+				}, "Volume mount sub path expression references environment variable which isn't defined")
+
+				continue
+			}
+
+			envs = append(envs, v1.EnvVar{
+				Name: name,
+				ValueFrom: &v1.EnvVarSource{
+					FieldRef: &v1.ObjectFieldSelector{
+						FieldPath: fieldPath,
+					},
+				},
+			})
+			defined[name] = true
+		}
+	}
+
+	return envs
+}
+
 // configEmptyVolumeSource is a helper function to create an EmptyDir v1.VolumeSource
 // either for Tmpfs or for emptyvolumes
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L894
@@ -1297,10 +2194,11 @@ func (k *Kubernetes) configConfigMapVolumeSource(cmName string, targetPath strin
 	}
 }
 
-// configHostPathVolumeSource is a helper function to create a HostPath v1.VolumeSource
+// configHostPathVolumeSource is a helper function to create a HostPath v1.VolumeSource.
+// Host path type is only set when specified, so that K8s doesn't check the host path by default.
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L935
-func (k *Kubernetes) configHostPathVolumeSource(path string) (*v1.VolumeSource, error) {
-	dir, err := getComposeFileDir(k.Opt.InputFiles)
+func (k *Kubernetes) configHostPathVolumeSource(path, hostPathType string) (*v1.VolumeSource, error) {
+	dir, err := k.composeFileDir()
 	if err != nil {
 		return nil, err
 	}
@@ -1310,8 +2208,14 @@ func (k *Kubernetes) configHostPathVolumeSource(path string) (*v1.VolumeSource,
 		absPath = filepath.Join(dir, path)
 	}
 
+	source := &v1.HostPathVolumeSource{Path: absPath}
+	if hostPathType != "" {
+		t := v1.HostPathType(hostPathType)
+		source.Type = &t
+	}
+
 	return &v1.VolumeSource{
-		HostPath: &v1.HostPathVolumeSource{Path: absPath},
+		HostPath: source,
 	}, nil
 }
 
@@ -1320,12 +2224,85 @@ func (k *Kubernetes) configHostPathVolumeSource(path string) (*v1.VolumeSource,
 func (k *Kubernetes) configPVCVolumeSource(name string, readonly bool) *v1.VolumeSource {
 	return &v1.VolumeSource{
 		PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
-			ClaimName: name,
+			ClaimName: k.dnsName(name),
 			ReadOnly:  readonly,
 		},
 	}
 }
 
+// envSecretName returns the name of the Secret generated for project service sensitive env var literal values
+func (k *Kubernetes) envSecretName(projectService ProjectService) string {
+	return k.dnsName(projectService.Name + "-env")
+}
+
+// initEnvSecret initialises a Secret holding project service env var values marked as sensitive
+// with the `secret:literal:` prefix. It returns nil when there are no such values.
+// NOTE: values must never be logged!
+func (k *Kubernetes) initEnvSecret(projectService ProjectService) *v1.Secret {
+	data := map[string][]byte{}
+	for name, v := range projectService.environment() {
+		if v != nil && strings.HasPrefix(*v, EnvSecretLiteralPrefix) {
+			data[name] = []byte(strings.TrimPrefix(*v, EnvSecretLiteralPrefix))
+		}
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	return &v1.Secret{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:   k.envSecretName(projectService),
+			Labels: configLabels(projectService.Name),
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: data,
+	}
+}
+
+// configEnvFrom returns a list of kubernetes EnvFromSource objects importing all keys of the referenced
+// project ConfigMaps (compose configs) and Secrets (compose secrets) as environment variables
+func (k *Kubernetes) configEnvFrom(projectService ProjectService) ([]v1.EnvFromSource, error) {
+	var sources []v1.EnvFromSource
+
+	for _, ref := range projectService.envFrom() {
+		if ref.ConfigMap != "" {
+			if _, ok := k.Project.Configs[ref.ConfigMap]; !ok {
+				return nil, fmt.Errorf("envFrom references config %s which isn't defined in the project", ref.ConfigMap)
+			}
+
+			sources = append(sources, v1.EnvFromSource{
+				Prefix: ref.Prefix,
+				ConfigMapRef: &v1.ConfigMapEnvSource{
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: k.dnsName(ref.ConfigMap),
+					},
+				},
+			})
+			continue
+		}
+
+		if _, ok := k.Project.Secrets[ref.Secret]; !ok {
+			return nil, fmt.Errorf("envFrom references secret %s which isn't defined in the project", ref.Secret)
+		}
+
+		sources = append(sources, v1.EnvFromSource{
+			Prefix: ref.Prefix,
+			SecretRef: &v1.SecretEnvSource{
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: k.dnsName(ref.Secret),
+				},
+			},
+		})
+	}
+
+	return sources, nil
+}
+
 // configEnvs returns a list of sorted kubernetes EnvVar objects mapping all project service environment variables
 // NOTE: compose-go library preloads all environment variables defined in env_files (if any), and appends
 // them to the list of explicitly provided environment variables.
@@ -1335,6 +2312,7 @@ func (k *Kubernetes) configEnvs(projectService ProjectService) ([]v1.EnvVar, err
 	envsWithDeps := []v1.EnvVar{}
 
 	refK8s := regexp.MustCompile(`^(config|pod|secret|container)\.[^\.]*\.[^\.]*`)
+	envSecretName := k.envSecretName(projectService)
 
 	// @step load up the environment variables
 	for k, v := range projectService.environment() {
@@ -1344,6 +2322,22 @@ func (k *Kubernetes) configEnvs(projectService ProjectService) ([]v1.EnvVar, err
 			v = &temp
 		}
 
+		// @step reference sensitive literal values from the generated env secret, see initEnvSecret
+		if strings.HasPrefix(*v, EnvSecretLiteralPrefix) {
+			envs = append(envs, v1.EnvVar{
+				Name: k,
+				ValueFrom: &v1.EnvVarSource{
+					SecretKeyRef: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{
+							Name: envSecretName,
+						},
+						Key: k,
+					},
+				},
+			})
+			continue
+		}
+
 		// @step generate EnvVar spec and handle special value reference cases for kubernetes `secret`, `configmap`, `pod` field or `container` resource
 		// e.g. `secret.my-secret-name.my-key`,
 		//      `config.my-config-name.config-key`,
@@ -1476,6 +2470,35 @@ func (k *Kubernetes) configEnvs(projectService ProjectService) ([]v1.EnvVar, err
 		}
 	}
 
+	// @step deliver secrets configured with an env var name via the `x-k8s.env` extension as secret key references
+	for _, secretConfig := range projectService.Secrets {
+		name := secretEnvName(secretConfig)
+		if name == "" {
+			continue
+		}
+
+		// @step secrets holding multiple keys can't be delivered as a single env var, they're mounted instead
+		if k.multiKeySecret(secretConfig.Source) {
+			k.warn(projectService.Name, "secrets", log.Fields{
+				"project-service": projectService.Name,
+				"secret":          secretConfig.Source,
+			}, "Secret holding multiple keys can't be delivered as an environment variable, mounting it instead")
+			continue
+		}
+
+		envs = append(envs, v1.EnvVar{
+			Name: name,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: k.dnsName(secretConfig.Source),
+					},
+					Key: k.secretDataKey(secretConfig.Source),
+				},
+			},
+		})
+	}
+
 	// Stable sorts data while keeping the original order of equal elements
 	// we need this because envs are not populated in any random order
 	// this sorting ensures they are populated in a particular order
@@ -1497,11 +2520,18 @@ func (k *Kubernetes) createKubernetesObjects(projectService ProjectService) []ru
 	// @step get workload type
 	workloadType := projectService.workloadType()
 
-	// @step create ConfigMap objects for compose project service (external are not supported!)
-	if len(projectService.Configs) > 0 {
-		objects = k.createConfigMapFromComposeConfig(projectService, objects)
+	// @step report compose job services converted to workloads other than Job
+	if d := projectService.Deploy; d != nil && (d.Mode == "replicated-job" || d.Mode == "global-job") && !config.WorkloadTypesEqual(workloadType, config.JobWorkload) {
+		k.warn(projectService.Name, "deploy.mode", log.Fields{
+			"project-service": projectService.Name,
+			"workload-type":   workloadType.String(),
+		}, fmt.Sprintf("Compose service defined as '%s' should map to K8s Job. Current configuration forces conversion to %s",
+			d.Mode, workloadType))
 	}
 
+	// @step create ConfigMap objects for compose project service (external are not supported!)
+	objects = k.createConfigMapFromComposeConfig(projectService, objects)
+
 	// @step create object based on inferred / manually configured workload controller type
 	var o runtime.Object
 
@@ -1514,6 +2544,31 @@ func (k *Kubernetes) createKubernetesObjects(projectService ProjectService) []ru
 		objects = append(objects, o)
 	case config.WorkloadTypesEqual(workloadType, config.DaemonSetWorkload):
 		objects = append(objects, k.initDaemonSet(projectService))
+	case config.WorkloadTypesEqual(workloadType, config.JobWorkload):
+		objects = append(objects, k.initJob(projectService, k.jobCompletions(projectService)))
+	}
+
+	// @step max unavailable pods during rolling update is only configurable for DaemonSets
+	if projectService.SvcK8sConfig.Workload.RollingUpdateMaxUnavailable != "" &&
+		!config.WorkloadTypesEqual(workloadType, config.DaemonSetWorkload) {
+		k.warn(projectService.Name, "workload.rollingUpdateMaxUnavailable", log.Fields{
+			"project-service": projectService.Name,
+			"workload-type":   workloadType.String(),
+		}, "Ignoring `rollingUpdateMaxUnavailable`, it only applies to DaemonSet workloads")
+	}
+
+	// @step report update strategies not supported by the workload type, the default strategy applies instead
+	switch updateStrategy := projectService.SvcK8sConfig.Workload.UpdateStrategy; {
+	case updateStrategy == string(v1apps.OnDeleteDaemonSetStrategyType) && config.WorkloadTypesEqual(workloadType, config.DeploymentWorkload):
+		k.warn(projectService.Name, "workload.updateStrategy", log.Fields{
+			"project-service": projectService.Name,
+			"workload-type":   workloadType.String(),
+		}, "Deployments can't be updated on delete, use `RollingUpdate` or `Recreate` update strategy instead")
+	case updateStrategy == string(v1apps.RecreateDeploymentStrategyType) && config.WorkloadTypesEqual(workloadType, config.DaemonSetWorkload):
+		k.warn(projectService.Name, "workload.updateStrategy", log.Fields{
+			"project-service": projectService.Name,
+			"workload-type":   workloadType.String(),
+		}, "DaemonSets can't be recreated, use `RollingUpdate` or `OnDelete` update strategy instead")
 	}
 
 	// @step create a horizontal pod autoscaler for eligible objects
@@ -1527,20 +2582,36 @@ func (k *Kubernetes) createKubernetesObjects(projectService ProjectService) []ru
 	// @step create a Service Account if speficied
 	if sa := k.initServiceAccount(projectService); sa != nil {
 		objects = append(objects, sa)
+
+		// @step create RBAC Role and RoleBinding for the Service Account if access rules are specified
+		if role, binding := k.initRBAC(projectService, sa); role != nil {
+			objects = append(objects, role, binding)
+		}
 	}
 
 	return objects
 }
 
 // createConfigMapFromComposeConfig will create ConfigMap objects for each non-external config
+// mounted by the project service or imported as its environment variables via envFrom
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L1078
 func (k *Kubernetes) createConfigMapFromComposeConfig(projectService ProjectService, objects []runtime.Object) []runtime.Object {
+	configNames := []string{}
 	for _, cfg := range projectService.Configs {
-		currentConfigName := cfg.Source
+		configNames = append(configNames, cfg.Source)
+	}
+
+	for _, ref := range projectService.envFrom() {
+		if _, ok := k.Project.Configs[ref.ConfigMap]; ok && !contains(configNames, ref.ConfigMap) {
+			configNames = append(configNames, ref.ConfigMap)
+		}
+	}
+
+	for _, currentConfigName := range configNames {
 		currentConfigObj := k.Project.Configs[currentConfigName]
 
 		if currentConfigObj.External.External {
-			log.WarnWithFields(log.Fields{
+			k.warn(projectService.Name, "configs", log.Fields{
 				"project-service": projectService.Name,
 				"config-name":     currentConfigName,
 			}, "Your deployment expects configmap to exist in the target K8s cluster namespace.")
@@ -1576,7 +2647,7 @@ func (k *Kubernetes) initPod(projectService ProjectService) *v1.Pod {
 		ObjectMeta: meta.ObjectMeta{
 			Name:        projectService.Name,
 			Labels:      configLabels(projectService.Name),
-			Annotations: configAnnotations(projectService.Labels, projectService.podAnnotations()),
+			Annotations: configAnnotations(projectService.labelAnnotations(), projectService.podAnnotations()),
 		},
 		Spec: k.initPodSpec(projectService),
 	}
@@ -1584,9 +2655,10 @@ func (k *Kubernetes) initPod(projectService ProjectService) *v1.Pod {
 	return &pod
 }
 
-// createNetworkPolicy initializes Network policy
+// createNetworkPolicy initializes Network policy restricting ingress traffic of pods attached to the network
+// to other pods attached to the same network
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L1109
-func (k *Kubernetes) createNetworkPolicy(_, networkName string) (*networking.NetworkPolicy, error) {
+func (k *Kubernetes) createNetworkPolicy(networkName string) (*networking.NetworkPolicy, error) {
 	str := "true"
 
 	np := &networking.NetworkPolicy{
@@ -1595,12 +2667,17 @@ func (k *Kubernetes) createNetworkPolicy(_, networkName string) (*networking.Net
 			APIVersion: "networking.k8s.io/v1",
 		},
 		ObjectMeta: meta.ObjectMeta{
-			Name: networkName,
+			Name: k.dnsName(networkName),
 			//Labels: ConfigLabels(name),
 		},
 		Spec: networking.NetworkPolicySpec{
 			PodSelector: meta.LabelSelector{
 				MatchLabels: map[string]string{NetworkLabel + "/" + networkName: str},
+				// pods of services which opted out of network policies on the same network aren't restricted
+				MatchExpressions: []meta.LabelSelectorRequirement{{
+					Key:      NetworkPolicyExemptLabel,
+					Operator: meta.LabelSelectorOpDoesNotExist,
+				}},
 			},
 			Ingress: []networking.NetworkPolicyIngressRule{{
 				From: []networking.NetworkPolicyPeer{{
@@ -1612,9 +2689,91 @@ func (k *Kubernetes) createNetworkPolicy(_, networkName string) (*networking.Net
 		},
 	}
 
+	// @step restrict egress traffic if configured in the network extension
+	netK8sConfig, err := config.NetK8sConfigFromCompose(k.networkConfig(networkName))
+	if err != nil {
+		return nil, err
+	}
+
+	// @step internal networks have no external connectivity, hence egress is restricted too
+	if netK8sConfig.Egress.Enabled || k.networkConfig(networkName).Internal {
+		np.Spec.PolicyTypes = []networking.PolicyType{networking.PolicyTypeIngress, networking.PolicyTypeEgress}
+		np.Spec.Egress = networkPolicyEgressRules(networkName, netK8sConfig.Egress)
+	}
+
 	return np, nil
 }
 
+// createDefaultDenyNetworkPolicy initializes a namespace wide network policy denying all ingress and egress
+// traffic, so that only traffic allowed by the per network policies is permitted
+func (k *Kubernetes) createDefaultDenyNetworkPolicy() *networking.NetworkPolicy {
+	return &networking.NetworkPolicy{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name: DefaultDenyNetworkPolicyName,
+		},
+		Spec: networking.NetworkPolicySpec{
+			PodSelector: meta.LabelSelector{},
+			PolicyTypes: []networking.PolicyType{networking.PolicyTypeIngress, networking.PolicyTypeEgress},
+		},
+	}
+}
+
+// networkConfig returns compose project network config by name
+func (k *Kubernetes) networkConfig(name string) *composego.NetworkConfig {
+	network := k.Project.Networks[name]
+	return &network
+}
+
+// networkPolicyEgressRules returns egress rules allowing traffic to pods on the same network,
+// to cluster DNS, and to explicitly allowed IP blocks
+func networkPolicyEgressRules(networkName string, egress config.NetworkEgress) []networking.NetworkPolicyEgressRule {
+	udp, tcp := v1.ProtocolUDP, v1.ProtocolTCP
+	dnsPort := intstr.FromInt(53)
+
+	rules := []networking.NetworkPolicyEgressRule{
+		{
+			To: []networking.NetworkPolicyPeer{{
+				PodSelector: &meta.LabelSelector{
+					MatchLabels: map[string]string{NetworkLabel + "/" + networkName: "true"},
+				},
+			}},
+		},
+		{
+			To: []networking.NetworkPolicyPeer{{
+				NamespaceSelector: &meta.LabelSelector{},
+				PodSelector: &meta.LabelSelector{
+					MatchLabels: map[string]string{KubeDNSLabel: KubeDNSLabelValue},
+				},
+			}},
+			Ports: []networking.NetworkPolicyPort{
+				{Protocol: &udp, Port: &dnsPort},
+				{Protocol: &tcp, Port: &dnsPort},
+			},
+		},
+	}
+
+	for _, allow := range egress.Allow {
+		rule := networking.NetworkPolicyEgressRule{
+			To: []networking.NetworkPolicyPeer{{
+				IPBlock: &networking.IPBlock{CIDR: allow.CIDR},
+			}},
+		}
+
+		for _, p := range allow.Ports {
+			port := intstr.FromInt(p)
+			rule.Ports = append(rule.Ports, networking.NetworkPolicyPort{Protocol: &tcp, Port: &port})
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
 // updateController updates the given object with the given pod template update function and ObjectMeta update function
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/kubernetes.go#L1254
 func (k *Kubernetes) updateController(obj runtime.Object, updateTemplate func(*v1.PodTemplateSpec) error, updateMeta func(meta *meta.ObjectMeta)) (err error) {
@@ -1685,7 +2844,7 @@ func (k *Kubernetes) createService(serviceType config.ServiceType, projectServic
 		svc.Spec.Type = v1SvcType
 	}
 
-	svc.ObjectMeta.Annotations = configAnnotations(projectService.Labels)
+	svc.ObjectMeta.Annotations = configAnnotations(projectService.labelAnnotations(), projectService.serviceAnnotations())
 
 	return svc, nil
 }
@@ -1709,11 +2868,38 @@ func (k *Kubernetes) createHeadlessService(projectService ProjectService) *v1.Se
 	svc.Spec.Ports = servicePorts
 	svc.Spec.ClusterIP = "None"
 
-	svc.ObjectMeta.Annotations = configAnnotations(projectService.Labels)
+	svc.ObjectMeta.Annotations = configAnnotations(projectService.labelAnnotations(), projectService.serviceAnnotations())
 
 	return svc
 }
 
+// createAliasServices creates a headless service per project service network alias, selecting the project service pods.
+// Network aliases allow compose services to be reached by alternate DNS names, which in K8s requires a Service per name.
+func (k *Kubernetes) createAliasServices(projectService ProjectService) []*v1.Service {
+	var svcs []*v1.Service
+
+	for _, alias := range projectService.networkAliases() {
+		name := k.labelName(alias)
+		if name != alias {
+			log.DebugfWithFields(log.Fields{
+				"project-service": projectService.Name,
+				"alias":           alias,
+			}, "Network alias normalised to %q", name)
+		}
+
+		svc := k.createHeadlessService(projectService)
+		svc.Name = name
+
+		if k.portsExist(projectService) {
+			svc.Spec.Ports = k.configServicePorts(config.HeadlessService, projectService)
+		}
+
+		svcs = append(svcs, svc)
+	}
+
+	return svcs
+}
+
 // updateKubernetesObjects updates k8s objects
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/k8sutils.go#L399
 func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, objects *[]runtime.Object) error {
@@ -1723,12 +2909,25 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 		return errors.Wrap(err, "Unable to load env variables")
 	}
 
+	envFrom, err := k.configEnvFrom(projectService)
+	if err != nil {
+		return errors.Wrap(err, "Unable to load env variables")
+	}
+
+	// @step add Secret holding sensitive env var values to objects
+	if secret := k.initEnvSecret(projectService); secret != nil {
+		*objects = append(*objects, secret)
+	}
+
 	// @step configure the container volumes
 	volumesMounts, volumes, pvcs, cms, err := k.configVolumes(projectService)
 	if err != nil {
 		return errors.Wrap(err, "Unable to configure container volumes")
 	}
 
+	// @step inject pod field env vars referenced by volume mount sub path expressions
+	envs = k.configSubPathExprEnvs(projectService, volumesMounts, envs)
+
 	// @step configure Tmpfs
 	if len(projectService.Tmpfs) > 0 {
 		TmpVolumesMount, TmpVolumes := k.configTmpfs(projectService)
@@ -1756,14 +2955,15 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 	capabilities := k.configCapabilities(projectService)
 
 	// @step configure annotations
-	annotations := configAnnotations(projectService.Labels)
+	annotations := configAnnotations(projectService.labelAnnotations(), projectService.workloadAnnotations())
 
 	// @step fillTemplate function will fill the pod template with the values calculated from config
 	fillTemplate := func(template *v1.PodTemplateSpec) error {
 		if len(projectService.ContainerName) > 0 {
-			template.Spec.Containers[0].Name = rfc1123dns(projectService.ContainerName)
+			template.Spec.Containers[0].Name = k.labelName(projectService.ContainerName)
 		}
 		template.Spec.Containers[0].Env = envs
+		template.Spec.Containers[0].EnvFrom = envFrom
 		template.Spec.Containers[0].Command = projectService.command()
 		template.Spec.Containers[0].Args = projectService.commandArgs()
 		template.Spec.Containers[0].WorkingDir = projectService.WorkingDir
@@ -1786,6 +2986,14 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 			template.Spec.Containers[0].LivenessProbe = healthCheck
 		}
 
+		// @step k8s requires liveness probes to succeed exactly once, the probe is rendered with a success threshold of 1
+		if threshold := projectService.SvcK8sConfig.Workload.LivenessProbe.SuccessThreshold; healthCheck != nil && threshold > config.DefaultProbeSuccessThreshold {
+			k.warn(projectService.Name, "workload.livenessProbe.successThreshold", log.Fields{
+				"project-service":   projectService.Name,
+				"success-threshold": threshold,
+			}, "Liveness probe success threshold must be 1, using 1 instead")
+		}
+
 		// @step configure readiness probe
 		// Note: This is not covered by the docker compose spec
 		readinessProbe, err := projectService.ReadinessProbe()
@@ -1798,6 +3006,9 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 		}
 		if readinessProbe != nil {
 			template.Spec.Containers[0].ReadinessProbe = readinessProbe
+		} else if k.Opt.MirrorHealthcheckToReadiness && projectService.HealthCheck != nil && healthCheck != nil {
+			// @step mirror the compose healthcheck when readiness isn't configured explicitly
+			template.Spec.Containers[0].ReadinessProbe = healthCheck.DeepCopy()
 		}
 
 		// @step configure pod termination grace priod
@@ -1815,8 +3026,49 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 			template.Spec.TerminationGracePeriodSeconds = &gracePeriod
 		}
 
+		// @step termination grace period specified in k8s extension overrides compose stop_grace_period
+		if gracePeriod := projectService.terminationGracePeriodSeconds(); gracePeriod != nil {
+			template.Spec.TerminationGracePeriodSeconds = gracePeriod
+		}
+
+		// @step configure service account token automount on the pod level
+		if automount := projectService.automountServiceAccountToken(); automount != nil {
+			template.Spec.AutomountServiceAccountToken = automount
+		}
+
+		// @step run the pod in its own user namespace when host users are disabled
+		if hostUsers := projectService.hostUsers(); hostUsers != nil {
+			template.Spec.HostUsers = hostUsers
+		}
+
+		// @step record the stop signal as K8s always sends SIGTERM to the container on pod termination
+		if signal := projectService.stopSignal(); signal != "" {
+			if template.ObjectMeta.Annotations == nil {
+				template.ObjectMeta.Annotations = map[string]string{}
+			}
+			template.ObjectMeta.Annotations[StopSignalAnnotation] = signal
+
+			if signal == "SIGTERM" {
+				log.DebugWithFields(log.Fields{
+					"project-service": projectService.Name,
+					"stop-signal":     signal,
+				}, "Stop signal matches the signal K8s sends on pod termination")
+			} else {
+				k.warn(projectService.Name, "stop_signal", log.Fields{
+					"project-service": projectService.Name,
+					"stop-signal":     signal,
+				}, "K8s sends SIGTERM on pod termination. Make sure the container handles SIGTERM or stops gracefully via a preStop hook.")
+			}
+		}
+
 		// @step configure pod resource requests and limits
-		k.setPodResources(projectService, template)
+		if err := k.setPodResources(projectService, template); err != nil {
+			log.ErrorWithFields(log.Fields{
+				"project-service": projectService.Name,
+			}, "Resource definition has errors")
+
+			return err
+		}
 
 		// @step configure pod security context
 		podSecurityContext := &v1.PodSecurityContext{}
@@ -1840,7 +3092,7 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 		template.Spec.Containers[0].Ports = ports
 
 		// @step update labels
-		template.ObjectMeta.Labels = configLabelsWithNetwork(projectService)
+		template.ObjectMeta.Labels = configPodLabels(projectService, k.Opt.PodDeployLabels)
 
 		// @step configure the image pull policy
 		template.Spec.Containers[0].ImagePullPolicy = projectService.imagePullPolicy()
@@ -1850,7 +3102,10 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 		if err != nil {
 			return err
 		}
-		template.Spec.RestartPolicy = restartPolicy
+		// keep restart policy already mapped for the workload type, e.g. for jobs
+		if template.Spec.RestartPolicy == "" {
+			template.Spec.RestartPolicy = restartPolicy
+		}
 
 		// @step configure hostname/domain_name settings
 		if projectService.Hostname != "" {
@@ -1865,7 +3120,7 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 
 	// @step fillObjectMeta fills the metadata with the value calculated from config
 	fillObjectMeta := func(meta *meta.ObjectMeta) {
-		meta.Annotations = annotations
+		meta.Annotations = configAnnotations(meta.Annotations, annotations)
 	}
 
 	// @step update supported k8s workload objects
@@ -1878,19 +3133,50 @@ func (k *Kubernetes) updateKubernetesObjects(projectService ProjectService, obje
 			return err
 		}
 
-		projectServiceVolumes, _ := projectService.volumes(k.Project)
-		if len(projectServiceVolumes) > 0 {
-			switch objType := obj.(type) {
-			// @todo Check if applicable to other object types
-			case *v1apps.Deployment:
-				objType.Spec.Strategy.Type = v1apps.RecreateDeploymentStrategyType
-			}
+		if d, ok := obj.(*v1apps.Deployment); ok {
+			configDeploymentStrategy(projectService, d, pvcs)
 		}
 	}
 
 	return nil
 }
 
+// configDeploymentStrategy sets deployment update strategy type as configured for the project service.
+// By default deployments mounting ReadWriteOnce volume claims get recreated, as new pods can't mount
+// volumes still attached to old pods running on other nodes. OnDelete strategy doesn't apply to deployments
+// and is treated as not specified.
+func configDeploymentStrategy(projectService ProjectService, d *v1apps.Deployment, pvcs []*v1.PersistentVolumeClaim) {
+	strategy := projectService.updateStrategy()
+	if strategy == v1apps.DeploymentStrategyType(v1apps.OnDeleteDaemonSetStrategyType) {
+		strategy = ""
+	}
+
+	if strategy == "" && hasReadWriteOnceClaim(pvcs) {
+		strategy = v1apps.RecreateDeploymentStrategyType
+	}
+
+	switch strategy {
+	case v1apps.RecreateDeploymentStrategyType:
+		// rolling update parameters aren't allowed with Recreate strategy
+		d.Spec.Strategy = v1apps.DeploymentStrategy{Type: strategy}
+	case v1apps.RollingUpdateDeploymentStrategyType:
+		d.Spec.Strategy.Type = strategy
+	}
+}
+
+// hasReadWriteOnceClaim tells whether any of the volume claims has ReadWriteOnce access mode
+func hasReadWriteOnceClaim(pvcs []*v1.PersistentVolumeClaim) bool {
+	for _, pvc := range pvcs {
+		for _, mode := range pvc.Spec.AccessModes {
+			if mode == v1.ReadWriteOnce {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // sortServicesFirst - sorts the objects so that services are first
 // according to best practice kubernetes services should be created first
 // http://kubernetes.io/docs/user-guide/config-best-practices/
@@ -1911,6 +3197,36 @@ func (k *Kubernetes) sortServicesFirst(objs *[]runtime.Object) {
 	*objs = ret
 }
 
+// warnServiceNameCollisions warns about Services selecting pods of different project services under the same name.
+// Only the first of such Services is kept when removing duplicate objects, so the others would be silently dropped.
+func (k *Kubernetes) warnServiceNameCollisions(objs []runtime.Object) {
+	owners := map[string]string{}
+
+	for _, obj := range objs {
+		svc, ok := obj.(*v1.Service)
+		if !ok {
+			continue
+		}
+
+		key := svc.Namespace + "/" + svc.Name
+		owner := svc.Spec.Selector[Selector]
+
+		existing, seen := owners[key]
+		if !seen {
+			owners[key] = owner
+			continue
+		}
+
+		if existing != owner {
+			k.warn(owner, "networks.aliases", log.Fields{
+				"project-service":     owner,
+				"service":             svc.Name,
+				"conflicting-service": existing,
+			}, fmt.Sprintf("Service %q collides with the one of %q project service and will be skipped", svc.Name, existing))
+		}
+	}
+}
+
 // removeDupObjects removes duplicate objects...
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/k8sutils.go#L679
 func (k *Kubernetes) removeDupObjects(objs *[]runtime.Object) {
@@ -1940,9 +3256,12 @@ func (k *Kubernetes) removeDupObjects(objs *[]runtime.Object) {
 
 // setPodResources configures pod resources
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/k8sutils.go#L592
-func (k *Kubernetes) setPodResources(projectService ProjectService, template *v1.PodTemplateSpec) {
+func (k *Kubernetes) setPodResources(projectService ProjectService, template *v1.PodTemplateSpec) error {
 	// @step resource limits
-	memLimit, cpuLimit, storageLimit := projectService.resourceLimits()
+	memLimit, cpuLimit, storageLimit, err := projectService.resourceLimits()
+	if err != nil {
+		return err
+	}
 
 	if *memLimit > 0 || *cpuLimit > 0 || *storageLimit > 0 {
 		resourceLimits := v1.ResourceList{}
@@ -1963,7 +3282,10 @@ func (k *Kubernetes) setPodResources(projectService ProjectService, template *v1
 	}
 
 	// @step resource requests
-	memRequest, cpuRequest, storageRequest := projectService.resourceRequests()
+	memRequest, cpuRequest, storageRequest, err := projectService.resourceRequests()
+	if err != nil {
+		return err
+	}
 
 	if *memRequest > 0 || *cpuRequest > 0 || *storageRequest > 0 {
 		resourceRequests := v1.ResourceList{}
@@ -1982,6 +3304,47 @@ func (k *Kubernetes) setPodResources(projectService ProjectService, template *v1
 
 		template.Spec.Containers[0].Resources.Requests = resourceRequests
 	}
+
+	// @step apply default resource requests to containers without any resources to avoid BestEffort pods.
+	// Note: containers with limits only aren't BestEffort as K8s defaults requests to limits.
+	resources := template.Spec.Containers[0].Resources
+	if len(resources.Requests) == 0 && len(resources.Limits) == 0 {
+		if defaultRequests := k.defaultResourceRequests(projectService); len(defaultRequests) > 0 {
+			template.Spec.Containers[0].Resources.Requests = defaultRequests
+		}
+	}
+
+	return nil
+}
+
+// defaultResourceRequests returns default resource requests as specified in convert options
+func (k *Kubernetes) defaultResourceRequests(projectService ProjectService) v1.ResourceList {
+	requests := v1.ResourceList{}
+
+	defaults := map[v1.ResourceName]string{
+		v1.ResourceCPU:    k.Opt.DefaultResourceRequests.CPU,
+		v1.ResourceMemory: k.Opt.DefaultResourceRequests.Memory,
+	}
+
+	for name, value := range defaults {
+		if value == "" {
+			continue
+		}
+
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			k.warn(projectService.Name, "resources", log.Fields{
+				"project-service": projectService.Name,
+				"resource":        string(name),
+				"quantity":        value,
+			}, "Ignoring default resource request as it's not a valid resource quantity")
+			continue
+		}
+
+		requests[name] = quantity
+	}
+
+	return requests
 }
 
 // setPodSecurityContext sets a pod security context
@@ -2002,7 +3365,7 @@ func (k *Kubernetes) setPodSecurityContext(projectService ProjectService, podSec
 		for _, g := range projectService.GroupAdd {
 			gid, err := strconv.ParseInt(g, 10, 64)
 			if err != nil {
-				log.WarnWithFields(log.Fields{
+				k.warn(projectService.Name, "group_add", log.Fields{
 					"project-service":    projectService.Name,
 					"supplemental-group": g,
 				}, "Ignoring supplemental group as it's not numeric. Supplemental groups must be specified as a GID (numeric).")
@@ -2025,7 +3388,7 @@ func (k *Kubernetes) setSecurityContext(projectService ProjectService, capabilit
 	if projectService.User != "" {
 		uid, err := strconv.ParseInt(projectService.User, 10, 64)
 		if err != nil {
-			log.WarnWithFields(log.Fields{
+			k.warn(projectService.Name, "user", log.Fields{
 				"project-service": projectService.Name,
 				"user":            projectService.User,
 			}, "Ignoring `user` directive value. User must be specified as a UID (numeric).")
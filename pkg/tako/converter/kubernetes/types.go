@@ -27,14 +27,47 @@ import (
 
 // ConvertOptions holds all options that controls transformation process
 type ConvertOptions struct {
-	ToStdout     bool     // Display output to STDOUT
-	CreateChart  bool     // Create K8s manifests as Chart
-	GenerateJSON bool     // Generate outcome as JSON. By defaults YAML gets generated.
-	EmptyVols    bool     // Treat all referenced volumes as Empty volumes
-	Volumes      string   // Volumes to be generated ("persistentVolumeClaim"|"emptyDir"|"hostPath"|"configMap") (default "persistentVolumeClaim")
-	InputFiles   []string // Compose files to be processed
-	OutFile      string   // If Directory output will be split into individual files
-	YAMLIndent   int      // YAML Indentation in resultant K8s manifests
+	ToStdout                     bool                 // Display output to STDOUT
+	CreateChart                  bool                 // Create K8s manifests as Chart
+	GenerateJSON                 bool                 // Generate outcome as JSON. By defaults YAML gets generated.
+	EmptyVols                    bool                 // Treat all referenced volumes as Empty volumes
+	Volumes                      string               // Volumes to be generated ("persistentVolumeClaim"|"emptyDir"|"hostPath"|"configMap") (default "persistentVolumeClaim")
+	InputFiles                   []string             // Compose files to be processed
+	WorkingDir                   string               // Base directory relative paths are resolved against. Defaults to the compose file directory, or the current directory without input files.
+	OutFile                      string               // If Directory output will be split into individual files
+	JSONLines                    bool                 // Generate outcome as JSON lines, one compact object per line. Implies GenerateJSON.
+	YAMLIndent                   int                  // YAML or JSON indentation in resultant K8s manifests
+	LongNames                    string               // Handling of names exceeding K8s length limits ("truncate"|"hash") (default "truncate")
+	ImageRegistryPrefix          string               // Registry prepended to workload images that don't specify a registry
+	DefaultResourceRequests      ResourceRequests     // Resource requests applied to containers that end up with no resources, to avoid BestEffort pods
+	BundleConfigMap              string               // If set, all rendered manifests are packed into a single ConfigMap with that name
+	PreserveServices             []string             // Services whose previously rendered manifests are preserved in the output directory
+	GenerateIndex                bool                 // Write an index of rendered manifests grouped by service and kind alongside the manifests
+	DisallowMutableTags          bool                 // Fail when any workload image is untagged or uses the "latest" tag
+	MaxObjectSize                int                  // Maximum size in bytes of ConfigMap and Secret data (default 1MiB)
+	StampSpecHash                bool                 // Annotate workloads with a hash of their rendered spec for change detection
+	DefaultNamespace             string               // Namespace set on all objects which don't specify one. By default objects have no namespace.
+	RenderDisabled               bool                 // Render disabled services annotated as disabled, with workloads that run no pods, instead of skipping them
+	LegacySecretKeys             bool                 // Store single file secret content under the secret name instead of the secret file base name
+	ExternalSecretStore          string               // SecretStore referenced by ExternalSecret objects generated for external secrets. By default external secrets are expected to exist in the cluster.
+	ImageNameTransformer         ImageNameTransformer // Maps workload image names, e.g. NormalizeImageName. Image names are left untouched when not set
+	Validate                     bool                 // Validate rendered objects against K8s API rules and fail on objects the cluster would reject
+	DefaultDenyNetworkPolicy     bool                 // Generate a namespace wide NetworkPolicy denying all ingress and egress traffic not allowed by network policies
+	PodDeployLabels              bool                 // Propagate compose deploy labels onto workload pod templates, not only onto the workload itself
+	IncludeKinds                 []string             // Render only objects of these kinds, e.g. Deployment. All kinds are rendered by default.
+	ExcludeKinds                 []string             // Skip rendering objects of these kinds, e.g. NetworkPolicy
+	MirrorHealthcheckToReadiness bool                 // Use the compose healthcheck as readiness probe too when no readiness probe is configured
+	ReportFile                   string               // Write a report of compose fields ignored during conversion to this file
+	IndexFileNames               bool                 // Prefix manifest file names with a zero padded index following the order objects should be applied in
+	NestedOutputLayout           bool                 // Write manifests to <namespace>/<kind>/<name>.yaml files instead of a flat output directory
+	OutputArchive                string               // Pack all rendered manifests into a gzip compressed tarball at this path instead of writing loose files
+	GenerateKustomization        bool                 // Write a kustomization.yaml listing rendered manifests alongside them
+}
+
+// ResourceRequests holds default container resource requests expressed as K8s resource quantities
+type ResourceRequests struct {
+	CPU    string // CPU request, e.g. 100m
+	Memory string // Memory request, e.g. 128Mi
 }
 
 // Volumes holds the container volume struct
@@ -50,6 +83,20 @@ type Volumes struct {
 	PVCSize       string // PVC size
 	StorageClass  string // PVC storage class
 	SelectorValue string // Value of the label selector
+	SubPath       string // Sub path within the volume to mount
+	SubPathExpr   string // Sub path within the volume to mount, expanded with container environment variables
+	Propagation   string // Mount propagation mode ("None"|"HostToContainer"|"Bidirectional")
+	ReadOnly      *bool  // Whether volume is mounted read-only, overrides access mode
+	HostPathType  string // Type of the host path checked before mounting it, e.g. "DirectoryOrCreate"
+}
+
+// RegistryAuth holds private container registry credentials used to generate an image pull secret
+type RegistryAuth struct {
+	Name     string `yaml:"name,omitempty"` // Name of the generated secret
+	Server   string `yaml:"server"`         // Registry server, e.g. ghcr.io
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Email    string `yaml:"email,omitempty"`
 }
 
 // ProjectService is a wrapper type around composego.ServiceConfig
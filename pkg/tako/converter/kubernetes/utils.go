@@ -22,8 +22,11 @@ package kubernetes
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
@@ -34,14 +37,20 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/appvia/tako/pkg/tako/config"
 	"github.com/appvia/tako/pkg/tako/log"
 	composego "github.com/compose-spec/compose-go/types"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
+	v1apps "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	v1batch "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -53,6 +62,124 @@ const (
 	NetworkLabel = "network"
 )
 
+// DefaultDenyNetworkPolicyName is the name of the namespace wide default deny network policy
+const DefaultDenyNetworkPolicyName = "default-deny"
+
+// BlockDevicePattern matches names of well known host block devices, i.e. disks, their partitions,
+// NVMe namespaces, loop devices and device mapper volumes. NVMe controllers, e.g. /dev/nvme0, are character devices.
+var BlockDevicePattern = regexp.MustCompile(`^/dev/((s|h|v|xv)d[a-z]+[0-9]*|nvme[0-9]+n[0-9]+(p[0-9]+)?|loop[0-9]+|mmcblk[0-9]+(p[0-9]+)?|dm-[0-9]+)$`)
+
+// KubeDNSLabel and KubeDNSLabelValue select cluster DNS pods network policies allow egress traffic to
+const (
+	KubeDNSLabel      = "k8s-app"
+	KubeDNSLabelValue = "kube-dns"
+)
+
+// NetworkPolicyExemptLabel marks pods of services which opted out of Network Policies
+const NetworkPolicyExemptLabel = "tako.appvia.io/network-policy-exempt"
+
+// StopSignalAnnotation records compose project service stop signal as K8s doesn't allow to configure it
+const StopSignalAnnotation = "io.kev.stop-signal"
+
+// ManagedAnnotation set to "false" in workload annotations makes the converter skip regenerating the service,
+// preserving its manually maintained manifests in the output directory
+const ManagedAnnotation = "tako.appvia.io/managed"
+
+// DisabledAnnotation marks objects rendered for a disabled service
+const DisabledAnnotation = "tako.appvia.io/disabled"
+
+// UpdateFailureActionAnnotation records compose update_config failure_action as K8s has no equivalent rollout setting
+const UpdateFailureActionAnnotation = "tako.appvia.io/update-failure-action"
+
+// DefaultMaxObjectSize is the maximum size of ConfigMap and Secret data, as K8s rejects objects over 1MiB
+const DefaultMaxObjectSize = 1024 * 1024
+
+// ManifestIndexFileName is a name of the rendered manifests index file
+const ManifestIndexFileName = "INDEX.md"
+
+// KustomizationFileName is a name of the kustomization listing rendered manifests
+const KustomizationFileName = "kustomization.yaml"
+
+// ManifestIndexOtherGroup groups indexed manifests that don't belong to any project service
+const ManifestIndexOtherGroup = "other"
+
+// NestedLayoutDefaultNamespace is a directory name of objects without namespace in the nested output layout
+const NestedLayoutDefaultNamespace = "default"
+
+// SpecHashAnnotation records a hash of the workload rendered spec for change detection
+const SpecHashAnnotation = "tako.appvia.io/spec-hash"
+
+// ConfigChecksumAnnotation records a checksum of ConfigMaps and Secrets data referenced by the workload pods,
+// so that pods get rolled out whenever that data changes
+const ConfigChecksumAnnotation = "io.kev/config-checksum"
+
+// RollbackConfigAnnotationPrefix prefixes annotations recording compose rollback_config as K8s has no equivalent
+const RollbackConfigAnnotationPrefix = "tako.appvia.io/rollback-"
+
+// SecretEnvExtensionKey is the key in the service secret `x-k8s` extension holding the name
+// of the environment variable the secret should be delivered as
+const SecretEnvExtensionKey = "env"
+
+const (
+	// SecretTypeExtensionKey is the key in the project secret `x-k8s` extension holding the secret type
+	SecretTypeExtensionKey = "type"
+
+	// SecretTLSType marks project secret as a TLS certificate and key pair
+	SecretTLSType = "tls"
+
+	// SecretTLSCertExtensionKey is the key in the TLS project secret `x-k8s` extension holding the certificate file path
+	SecretTLSCertExtensionKey = "cert"
+
+	// SecretTLSKeyExtensionKey is the key in the TLS project secret `x-k8s` extension holding the private key file path
+	SecretTLSKeyExtensionKey = "key"
+
+	// SecretStoreExtensionKey is the key in the external project secret `x-k8s` extension holding
+	// the name of External Secrets Operator SecretStore the secret is fetched from
+	SecretStoreExtensionKey = "store"
+
+	// SecretRemoteKeyExtensionKey is the key in the external project secret `x-k8s` extension holding
+	// the key of the secret in the external secrets provider
+	SecretRemoteKeyExtensionKey = "remoteKey"
+)
+
+// ExternalSecretAPIVersion is the External Secrets Operator API version of generated ExternalSecret objects
+const ExternalSecretAPIVersion = "external-secrets.io/v1beta1"
+
+// SubPathExprPodFields maps env vars injected when referenced by volume mount sub path expressions to pod fields
+var SubPathExprPodFields = map[string]string{
+	"POD_NAME":      "metadata.name",
+	"POD_NAMESPACE": "metadata.namespace",
+	"POD_IP":        "status.podIP",
+	"NODE_NAME":     "spec.nodeName",
+}
+
+// PortNameExtensionKey is the key in the service port `x-k8s` extension holding the container port name
+const PortNameExtensionKey = "name"
+
+// RegistryAuthExtensionKey is the project extension key holding private container registry credentials
+const RegistryAuthExtensionKey = "x-k8s-registry-auth"
+
+// DefaultRegistryAuthSecretName is the default name of the image pull secret generated from registry credentials
+const DefaultRegistryAuthSecretName = "registry-auth"
+
+// EnvSecretLiteralPrefix marks a sensitive environment variable literal value to be delivered via a generated Secret
+const EnvSecretLiteralPrefix = "secret:literal:"
+
+// K8sExtensionRefKey is the key in the service `x-k8s` extension referencing an external file with its contents
+const K8sExtensionRefKey = "$ref"
+
+const (
+	// LongNamesTruncate truncates names exceeding K8s length limits
+	LongNamesTruncate = "truncate"
+
+	// LongNamesHash truncates names exceeding K8s length limits and appends a hash suffix to keep them unique
+	LongNamesHash = "hash"
+
+	labelNameMaxLength   = 63
+	dnsNameMaxLength     = 253
+	nameHashSuffixLength = 8
+)
+
 // EnvSort struct
 type EnvSort []v1.EnvVar
 
@@ -77,6 +204,16 @@ func (env EnvSort) Swap(i, j int) {
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/k8sutils.go#L153
 func PrintList(objects []runtime.Object, opt ConvertOptions, additionalManifests []string, rendered map[string][]byte) error {
 
+	// @step pack manifests into an archive instead of writing loose files when requested
+	if opt.OutputArchive != "" {
+		return printArchive(objects, opt, additionalManifests, rendered)
+	}
+
+	// @step stream manifests to stdout as a single list object
+	if opt.ToStdout {
+		return PrintListTo(os.Stdout, objects, opt, additionalManifests)
+	}
+
 	var f *os.File
 	dirName := getDirName(opt)
 	log.Debugf("Target Dir: %s", dirName)
@@ -94,6 +231,12 @@ func PrintList(objects []runtime.Object, opt ConvertOptions, additionalManifests
 	}
 
 	if !isDirVal {
+		if len(opt.PreserveServices) > 0 {
+			log.WarnWithFields(log.Fields{
+				"project-services": strings.Join(opt.PreserveServices, ","),
+			}, "Manifests of services not managed by the converter can't be preserved when rendering to a single file")
+		}
+
 		// cleanup target directory before creating a new file
 		if err := os.RemoveAll(filepath.Dir(dirName)); err != nil {
 			return err
@@ -118,55 +261,23 @@ func PrintList(objects []runtime.Object, opt ConvertOptions, additionalManifests
 		}(f)
 	}
 
-	indent := 2
-	if opt.YAMLIndent > 0 {
-		indent = opt.YAMLIndent
-	}
-
-	// @step print to stdout, or to a single file - it will return a list object
-	if opt.ToStdout || f != nil {
-
-		list := &v1.List{}
-
-		// convert objects to versioned and add them to list
-		for _, object := range objects {
-			versionedObject, err := convertToVersion(object, schema.GroupVersion{})
-			if err != nil {
-				return err
-			}
-
-			list.Items = append(list.Items, runtime.RawExtension{Object: versionedObject})
-		}
-
-		// if additional manifests files are specified, add them to the generated objects list
-		if len(additionalManifests) > 0 {
-			for _, extraManifest := range additionalManifests {
-
-				ro, err := fileToRuntimeObject(extraManifest)
-				if err != nil {
-					return err
-				}
+	opt, indent := outputFormat(opt)
 
-				list.Items = append(list.Items, runtime.RawExtension{Object: ro})
-			}
-		}
+	// @step order objects canonically and pack them into a ConfigMap bundle when requested
+	objects, additionalManifests, err = bundleObjects(objects, opt, additionalManifests, indent)
+	if err != nil {
+		return err
+	}
 
-		// version list itself
-		listVersion := schema.GroupVersion{Group: "", Version: "v1"}
-		list.Kind = "List"
-		list.APIVersion = "v1"
-		convertedList, err := convertToVersion(list, listVersion)
-		if err != nil {
-			return err
-		}
+	// @step print to a single file - it will return a list object
+	if f != nil {
 
-		data, err := marshal(convertedList, opt.GenerateJSON, indent)
+		data, err := marshalList(objects, opt, additionalManifests, indent)
 		if err != nil {
-			log.Error("Error in marshalling the List")
 			return err
 		}
 
-		file, err := print(dirName, "", "", data, opt.ToStdout, opt.GenerateJSON, f)
+		file, err := print(dirName, "", "", data, opt.GenerateJSON, f)
 		if err != nil {
 			log.Error("Printing manifests failed")
 			return err
@@ -184,7 +295,7 @@ func PrintList(objects []runtime.Object, opt ConvertOptions, additionalManifests
 			finalDirName = filepath.Join(dirName, "templates")
 		}
 
-		if err := os.RemoveAll(finalDirName); err != nil {
+		if err := cleanOutputDir(finalDirName, opt.PreserveServices, rendered); err != nil {
 			return err
 		}
 
@@ -205,42 +316,41 @@ func PrintList(objects []runtime.Object, opt ConvertOptions, additionalManifests
 			}
 		}
 
+		// @step keep additional manifests in canonical order too, so that indexed file names follow the apply order
+		objects = sortObjects(objects)
+
 		// create a separate file for each provider
-		for _, v := range objects {
+		for i, v := range objects {
 
 			versionedObject, err := convertToVersion(v, schema.GroupVersion{})
 			if err != nil {
 				return err
 			}
 
-			data, err := marshal(versionedObject, opt.GenerateJSON, indent)
+			var data []byte
+			if opt.JSONLines {
+				data, err = marshalLines([]runtime.Object{versionedObject})
+			} else {
+				data, err = marshal(versionedObject, opt.GenerateJSON, indent)
+			}
 			if err != nil {
 				return err
 			}
 
-			var typeMeta meta.TypeMeta
-			var objectMeta meta.ObjectMeta
+			typeMeta, objectMeta := objectMetas(v)
 
-			if us, ok := v.(*unstructured.Unstructured); ok {
-				typeMeta = meta.TypeMeta{
-					Kind:       us.GetKind(),
-					APIVersion: us.GetAPIVersion(),
-				}
-				objectMeta = meta.ObjectMeta{
-					Name: us.GetName(),
-				}
-			} else {
-				val := reflect.ValueOf(v).Elem()
-				// Use reflect to access TypeMeta struct inside runtime.Object.
-				// cast it to correct type - meta.TypeMeta
-				typeMeta = val.FieldByName("TypeMeta").Interface().(meta.TypeMeta)
-
-				// Use reflect to access ObjectMeta struct inside runtime.Object.
-				// cast it to correct type - meta.ObjectMeta
-				objectMeta = val.FieldByName("ObjectMeta").Interface().(meta.ObjectMeta)
+			// @step prefix file names with a zero padded index to keep the apply order when requested
+			name := objectMeta.Name
+			if opt.IndexFileNames {
+				name = fmt.Sprintf("%03d-%s", i+1, name)
 			}
 
-			file, err := print(finalDirName, objectMeta.Name, strings.ToLower(typeMeta.Kind), data, opt.ToStdout, opt.GenerateJSON, f)
+			var file string
+			if opt.NestedOutputLayout {
+				file, err = printNested(finalDirName, objectMeta.Namespace, name, strings.ToLower(typeMeta.Kind), data, opt.GenerateJSON)
+			} else {
+				file, err = print(finalDirName, name, strings.ToLower(typeMeta.Kind), data, opt.GenerateJSON, f)
+			}
 			if err != nil {
 				log.Error("Printing manifests failed")
 				return err
@@ -248,7 +358,45 @@ func PrintList(objects []runtime.Object, opt ConvertOptions, additionalManifests
 
 			rendered[file] = data
 		}
+
+		// @step write an index of all rendered manifests when requested
+		if opt.GenerateIndex {
+			file := filepath.Join(dirName, ManifestIndexFileName)
+			data := manifestIndex(dirName, rendered)
+
+			if err := os.WriteFile(file, data, 0644); err != nil {
+				log.ErrorWithFields(log.Fields{
+					"file": file,
+				}, "Failed to write manifests index")
+				return err
+			}
+
+			rendered[file] = data
+		}
+	}
+	// @step write a kustomization listing all rendered manifests when requested
+	if opt.GenerateKustomization {
+		kustomizationDir := dirName
+		if f != nil {
+			kustomizationDir = filepath.Dir(dirName)
+		}
+
+		file := filepath.Join(kustomizationDir, KustomizationFileName)
+		data, err := kustomization(kustomizationDir, rendered)
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			log.ErrorWithFields(log.Fields{
+				"file": file,
+			}, "Failed to write kustomization")
+			return err
+		}
+
+		rendered[file] = data
 	}
+
 	// @step for helm output generate chart directory structure
 	if opt.CreateChart {
 		if err = generateHelm(dirName); err != nil {
@@ -260,6 +408,454 @@ func PrintList(objects []runtime.Object, opt ConvertOptions, additionalManifests
 	return nil
 }
 
+// PrintListTo writes k8s objects to the writer as a single list, or as JSON lines when requested.
+// It allows to stream manifests into other tools without intermediate files.
+func PrintListTo(w io.Writer, objects []runtime.Object, opt ConvertOptions, additionalManifests []string) error {
+	opt, indent := outputFormat(opt)
+
+	objects, additionalManifests, err := bundleObjects(objects, opt, additionalManifests, indent)
+	if err != nil {
+		return err
+	}
+
+	data, err := marshalList(objects, opt, additionalManifests, indent)
+	if err != nil {
+		return err
+	}
+
+	return writeManifests(w, data)
+}
+
+// outputFormat returns options adjusted to the requested output format and the indentation to use
+func outputFormat(opt ConvertOptions) (ConvertOptions, int) {
+	indent := 2
+	if opt.YAMLIndent > 0 {
+		indent = opt.YAMLIndent
+	}
+
+	// JSON lines are JSON too, e.g. files get the .json extension
+	if opt.JSONLines {
+		opt.GenerateJSON = true
+	}
+
+	return opt, indent
+}
+
+// bundleObjects returns objects in canonical order, packed into a single ConfigMap bundle when requested
+func bundleObjects(objects []runtime.Object, opt ConvertOptions, additionalManifests []string, indent int) ([]runtime.Object, []string, error) {
+	objects = sortObjects(objects)
+
+	if opt.BundleConfigMap != "" {
+		name := truncateName(rfc1123(opt.BundleConfigMap), dnsNameMaxLength, opt.LongNames == LongNamesHash)
+		bundle, err := bundleConfigMap(name, objects, additionalManifests, opt.GenerateJSON, indent)
+		if err != nil {
+			log.Error("Error bundling manifests into a ConfigMap")
+			return nil, nil, err
+		}
+
+		objects = []runtime.Object{bundle}
+		additionalManifests = nil
+	}
+
+	return objects, additionalManifests, nil
+}
+
+// marshalList marshals objects and additional manifests as a single versioned List object
+func marshalList(objects []runtime.Object, opt ConvertOptions, additionalManifests []string, indent int) ([]byte, error) {
+	list := &v1.List{}
+
+	// convert objects to versioned and add them to list
+	for _, object := range objects {
+		versionedObject, err := convertToVersion(object, schema.GroupVersion{})
+		if err != nil {
+			return nil, err
+		}
+
+		list.Items = append(list.Items, runtime.RawExtension{Object: versionedObject})
+	}
+
+	// if additional manifests files are specified, add them to the generated objects list
+	if len(additionalManifests) > 0 {
+		for _, extraManifest := range additionalManifests {
+
+			ro, err := fileToRuntimeObject(extraManifest)
+			if err != nil {
+				return nil, err
+			}
+
+			list.Items = append(list.Items, runtime.RawExtension{Object: ro})
+		}
+	}
+
+	// version list itself
+	listVersion := schema.GroupVersion{Group: "", Version: "v1"}
+	list.Kind = "List"
+	list.APIVersion = "v1"
+	convertedList, err := convertToVersion(list, listVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if opt.JSONLines {
+		// @step JSON lines output lists every object on its own line, without the List wrapper
+		items := []runtime.Object{}
+		for _, item := range list.Items {
+			items = append(items, item.Object)
+		}
+		data, err = marshalLines(items)
+	} else {
+		data, err = marshal(convertedList, opt.GenerateJSON, indent)
+	}
+	if err != nil {
+		log.Error("Error in marshalling the List")
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// cleanOutputDir removes previously rendered manifests from the output directory,
+// except for manifests belonging to the preserved project services
+func cleanOutputDir(dir string, preserve []string, rendered map[string][]byte) error {
+	if len(preserve) == 0 {
+		return os.RemoveAll(dir)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, e := range entries {
+		file := filepath.Join(dir, e.Name())
+
+		// @step clean nested output layout directories, removing them once empty
+		if e.IsDir() {
+			if err := cleanOutputDir(file, preserve, rendered); err != nil {
+				return err
+			}
+
+			if remaining, err := os.ReadDir(file); err == nil && len(remaining) > 0 {
+				continue
+			}
+		}
+
+		// @step keep manifests of preserved services and report them as rendered
+		if !e.IsDir() {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+
+			var manifest struct {
+				Metadata struct {
+					Labels map[string]string `yaml:"labels"`
+				} `yaml:"metadata"`
+			}
+
+			if err := yaml.Unmarshal(data, &manifest); err == nil && contains(preserve, manifest.Metadata.Labels[Selector]) {
+				log.DebugfWithFields(log.Fields{
+					"project-service": manifest.Metadata.Labels[Selector],
+				}, "Preserving manifest %q of service not managed by the converter", file)
+
+				rendered[file] = data
+				continue
+			}
+		}
+
+		if err := os.RemoveAll(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// objectMetas returns type and object metadata of a runtime object
+func objectMetas(v runtime.Object) (meta.TypeMeta, meta.ObjectMeta) {
+	if us, ok := v.(*unstructured.Unstructured); ok {
+		typeMeta := meta.TypeMeta{
+			Kind:       us.GetKind(),
+			APIVersion: us.GetAPIVersion(),
+		}
+		objectMeta := meta.ObjectMeta{
+			Name:      us.GetName(),
+			Namespace: us.GetNamespace(),
+		}
+		return typeMeta, objectMeta
+	}
+
+	val := reflect.ValueOf(v).Elem()
+	// Use reflect to access TypeMeta struct inside runtime.Object.
+	// cast it to correct type - meta.TypeMeta
+	typeMeta := val.FieldByName("TypeMeta").Interface().(meta.TypeMeta)
+
+	// Use reflect to access ObjectMeta struct inside runtime.Object.
+	// cast it to correct type - meta.ObjectMeta
+	objectMeta := val.FieldByName("ObjectMeta").Interface().(meta.ObjectMeta)
+
+	return typeMeta, objectMeta
+}
+
+// bundleConfigMap packs rendered objects and additional manifests into a single ConfigMap keyed by manifest file name
+func bundleConfigMap(name string, objects []runtime.Object, additionalManifests []string, generateJSON bool, indent int) (*v1.ConfigMap, error) {
+	// @step include additional manifests in the bundle
+	for _, extraManifest := range additionalManifests {
+		ro, err := fileToRuntimeObject(extraManifest)
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, ro)
+	}
+
+	data := map[string]string{}
+
+	// @step add each rendered object under its manifest file name
+	for _, v := range objects {
+		versionedObject, err := convertToVersion(v, schema.GroupVersion{})
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := marshal(versionedObject, generateJSON, indent)
+		if err != nil {
+			return nil, err
+		}
+
+		typeMeta, objectMeta := objectMetas(v)
+		data[manifestFileName(objectMeta.Name, strings.ToLower(typeMeta.Kind), generateJSON)] = string(content)
+	}
+
+	return &v1.ConfigMap{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name: rfc1123dns(name),
+		},
+		Data: data,
+	}, nil
+}
+
+// manifestIndex returns a markdown index of manifests rendered into the dir, grouped by project service and kind
+func manifestIndex(dir string, rendered map[string][]byte) []byte {
+	type entry struct {
+		file string
+		name string
+	}
+
+	// service -> kind -> entries
+	index := map[string]map[string][]entry{}
+
+	for file, data := range rendered {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		var manifest struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name   string            `yaml:"name"`
+				Labels map[string]string `yaml:"labels"`
+			} `yaml:"metadata"`
+		}
+
+		if err := yaml.Unmarshal(data, &manifest); err != nil {
+			continue
+		}
+
+		service := manifest.Metadata.Labels[Selector]
+		if service == "" {
+			service = ManifestIndexOtherGroup
+		}
+
+		if _, ok := index[service]; !ok {
+			index[service] = map[string][]entry{}
+		}
+
+		index[service][manifest.Kind] = append(index[service][manifest.Kind], entry{
+			file: filepath.ToSlash(rel),
+			name: manifest.Metadata.Name,
+		})
+	}
+
+	var b strings.Builder
+	b.WriteString("# Manifests index\n")
+
+	for _, service := range sortedKeys(index) {
+		fmt.Fprintf(&b, "\n## %s\n", service)
+
+		for _, kind := range sortedKeys(index[service]) {
+			entries := index[service][kind]
+			sort.Slice(entries, func(i, j int) bool { return entries[i].file < entries[j].file })
+
+			fmt.Fprintf(&b, "\n### %s\n\n", kind)
+			for _, e := range entries {
+				fmt.Fprintf(&b, "- [%s](%s) - %s `%s`\n", e.file, e.file, kind, e.name)
+			}
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// kustomization returns a kustomization listing manifests rendered into the dir as its resources
+func kustomization(dir string, rendered map[string][]byte) ([]byte, error) {
+	resources := []string{}
+
+	for file := range rendered {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		// skip anything that isn't a manifest, e.g. manifests index
+		switch filepath.Ext(file) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+
+		if filepath.Base(file) == KustomizationFileName {
+			continue
+		}
+
+		resources = append(resources, filepath.ToSlash(rel))
+	}
+	sort.Strings(resources)
+
+	return yaml.Marshal(map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  resources,
+	})
+}
+
+// sortedKeys returns map keys sorted alphabetically
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resolveK8sExtensionRef returns service extensions with the x-k8s extension loaded from an external file
+// when it's referenced with `$ref`. Referenced file path is relative to the compose file directory.
+func (k *Kubernetes) resolveK8sExtensionRef(svcExtensions map[string]interface{}) (map[string]interface{}, error) {
+	dir, err := k.composeFileDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return ResolveK8sExtensionRef(svcExtensions, dir)
+}
+
+// ResolveK8sExtensionRef returns service extensions with the x-k8s extension loaded from an external file
+// when it's referenced with `$ref`. Relative file paths are resolved against the dir.
+// Any values specified inline next to the reference take precedence over referenced ones.
+func ResolveK8sExtensionRef(svcExtensions map[string]interface{}, dir string) (map[string]interface{}, error) {
+	svcK8s, ok := svcExtensions[config.K8SExtensionKey].(map[string]interface{})
+	if !ok {
+		return svcExtensions, nil
+	}
+
+	ref, ok := svcK8s[K8sExtensionRefKey]
+	if !ok {
+		return svcExtensions, nil
+	}
+
+	refPath, ok := ref.(string)
+	if !ok || strings.TrimSpace(refPath) == "" {
+		return nil, fmt.Errorf("%s `%s` must be a file path", config.K8SExtensionKey, K8sExtensionRefKey)
+	}
+
+	if !filepath.IsAbs(refPath) {
+		refPath = filepath.Join(dir, refPath)
+	}
+
+	data, err := os.ReadFile(refPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read %s extension file", config.K8SExtensionKey)
+	}
+
+	referenced := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &referenced); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s extension file %s", config.K8SExtensionKey, refPath)
+	}
+
+	inline := make(map[string]interface{}, len(svcK8s))
+	for k, v := range svcK8s {
+		if k != K8sExtensionRefKey {
+			inline[k] = v
+		}
+	}
+
+	extensions := make(map[string]interface{}, len(svcExtensions))
+	for k, v := range svcExtensions {
+		extensions[k] = v
+	}
+	extensions[config.K8SExtensionKey] = deepMergeMaps(referenced, inline)
+
+	return extensions, nil
+}
+
+// WithProjectK8sDefaults returns service extensions with the project level x-k8s extension
+// deep merged under the service's own x-k8s extension. Service values always win.
+// Note that compose only keeps top level extensions of the base compose file, those of override files are dropped.
+func WithProjectK8sDefaults(svcExtensions, projectExtensions map[string]interface{}) map[string]interface{} {
+	defaults, ok := projectExtensions[config.K8SExtensionKey].(map[string]interface{})
+	if !ok || len(defaults) == 0 {
+		return svcExtensions
+	}
+
+	extensions := make(map[string]interface{}, len(svcExtensions)+1)
+	for k, v := range svcExtensions {
+		extensions[k] = v
+	}
+
+	svcK8s, _ := svcExtensions[config.K8SExtensionKey].(map[string]interface{})
+	extensions[config.K8SExtensionKey] = deepMergeMaps(defaults, svcK8s)
+
+	return extensions
+}
+
+// deepMergeMaps returns a new map with override values deep merged on top of base values
+func deepMergeMaps(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+
+	for k, v := range override {
+		baseMap, baseIsMap := merged[k].(map[string]interface{})
+		overrideMap, overrideIsMap := v.(map[string]interface{})
+		if baseIsMap && overrideIsMap {
+			merged[k] = deepMergeMaps(baseMap, overrideMap)
+			continue
+		}
+		merged[k] = v
+	}
+
+	return merged
+}
+
+// manifestFileName returns the file name of a rendered manifest
+func manifestFileName(name, kind string, generateJSON bool) string {
+	if generateJSON {
+		return fmt.Sprintf("%s-%s.json", name, kind)
+	}
+	return fmt.Sprintf("%s-%s.yaml", name, kind)
+}
+
 // fileToRuntimeObject reads a file and converts its contents to a runtime.Object
 func fileToRuntimeObject(file string) (runtime.Object, error) {
 	// @step: create a new decoder
@@ -284,28 +880,19 @@ func fileToRuntimeObject(file string) (runtime.Object, error) {
 	return runtimeObject, nil
 }
 
-// print either renders to stdout or to file/s
+// print renders either to a single file or to file/s
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/utils.go#L176
-func print(path, name, kind string, data []byte, toStdout, generateJSON bool, f *os.File) (string, error) {
-	file := ""
-
-	if generateJSON {
-		file = fmt.Sprintf("%s-%s.json", name, kind)
-	} else {
-		file = fmt.Sprintf("%s-%s.yaml", name, kind)
-	}
-
-	if toStdout {
-		_, _ = fmt.Fprintf(os.Stdout, "%s\n", string(data))
-		return "", nil
+func print(path, name, kind string, data []byte, generateJSON bool, f *os.File) (string, error) {
+	file := manifestFileName(name, kind, generateJSON)
 
-	} else if f != nil {
+	if f != nil {
 		// Write all content to a single file f
-		if _, err := f.WriteString(fmt.Sprintf("%s\n", string(data))); err != nil {
+		if err := writeManifests(f, data); err != nil {
 			log.Error("Couldn't write manifests content to a single file")
 			return "", err
 		}
 		_ = f.Sync()
+		file = f.Name()
 
 	} else {
 		// Write content separately to each file
@@ -322,6 +909,40 @@ func print(path, name, kind string, data []byte, toStdout, generateJSON bool, f
 	return file, nil
 }
 
+// writeManifests writes rendered manifests content to the writer
+func writeManifests(w io.Writer, data []byte) error {
+	_, err := fmt.Fprintf(w, "%s\n", string(data))
+	return err
+}
+
+// printNested writes object content to `<namespace>/<kind>/<name>` file under the path and returns the file path
+func printNested(path, namespace, name, kind string, data []byte, generateJSON bool) (string, error) {
+	if namespace == "" {
+		namespace = NestedLayoutDefaultNamespace
+	}
+
+	dir := filepath.Join(path, namespace, kind)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	ext := ".yaml"
+	if generateJSON {
+		ext = ".json"
+	}
+
+	file := filepath.Join(dir, name+ext)
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		log.ErrorWithFields(log.Fields{
+			"file": file,
+		}, "Failed to write content to a file")
+		return "", err
+	}
+	log.Debugf("%s file %q created", Name, file)
+
+	return file, nil
+}
+
 // Generate Helm Chart configuration
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/k8sutils.go#L54
 func generateHelm(dirName string) error {
@@ -329,7 +950,7 @@ func generateHelm(dirName string) error {
 		Name string
 	}
 
-	details := ChartDetails{dirName}
+	details := ChartDetails{filepath.Base(dirName)}
 	manifestDir := dirName + string(os.PathSeparator) + "templates"
 	dir, err := os.Open(dirName)
 
@@ -381,6 +1002,13 @@ home:
 		return err
 	}
 
+	// @step Create the values.yaml file so the chart can be deployed with an environment values file
+	values := "# Default values for " + filepath.Base(dirName) + "\n"
+	err = os.WriteFile(dirName+string(os.PathSeparator)+"values.yaml", []byte(values), 0644)
+	if err != nil {
+		return err
+	}
+
 	log.Debugf("chart created in %q", dirName+string(os.PathSeparator))
 	return nil
 }
@@ -473,19 +1101,44 @@ func marshal(obj runtime.Object, jsonFormat bool, indent int) ([]byte, error) {
 	}
 
 	// convert data to yaml or json
+	// Note: both encoders sort map keys, e.g. labels, annotations or ConfigMap data, so
+	// re-rendering unchanged objects produces byte identical output
 	switch jsonFormat {
 	case true:
-		return json.MarshalIndent(jsonObj, "", "  ")
+		return json.MarshalIndent(jsonObj, "", strings.Repeat(" ", indent))
+
+	default:
+		var b bytes.Buffer
+		encoder := yaml.NewEncoder(&b)
+		encoder.SetIndent(indent)
+		if err := encoder.Encode(jsonObj); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+}
+
+// marshalLines marshals objects as JSON lines, each object compacted into a single line.
+// Like other marshalled content, the result has no trailing new line.
+func marshalLines(objs []runtime.Object) ([]byte, error) {
+	var b bytes.Buffer
+	for _, obj := range objs {
+		jsonObj, err := sanitizeObject(obj)
+		if err != nil {
+			return nil, err
+		}
 
-	default:
-		var b bytes.Buffer
-		encoder := yaml.NewEncoder(&b)
-		encoder.SetIndent(indent)
-		if err := encoder.Encode(jsonObj); err != nil {
+		data, err := json.Marshal(jsonObj)
+		if err != nil {
 			return nil, err
 		}
-		return b.Bytes(), nil
+
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.Write(data)
 	}
+	return b.Bytes(), nil
 }
 
 // convertToVersion converts object to a versioned object
@@ -554,6 +1207,47 @@ func sortServices(project *composego.Project) {
 	})
 }
 
+// parseCPU parses a CPU quantity given either as a number of CPUs, e.g. 0.5, or in millicpu, e.g. 500m,
+// and returns it in millicpu
+func parseCPU(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if !config.IsCPUQuantity(s) {
+		return 0, fmt.Errorf("invalid CPU value %q, use a number of CPUs, e.g. 0.5, or millicpu, e.g. 500m", s)
+	}
+
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid CPU value %q, use a number of CPUs, e.g. 0.5, or millicpu, e.g. 500m", s)
+	}
+
+	return q.MilliValue(), nil
+}
+
+// parseDevice splits a compose device mapping, e.g. `/dev/snd:/dev/snd:rwm`, into host and container paths.
+// The container path defaults to the host path when omitted.
+func parseDevice(device string) (string, string) {
+	parts := strings.Split(strings.TrimSpace(device), ":")
+
+	host := parts[0]
+	container := host
+	if len(parts) > 1 && parts[1] != "" {
+		container = parts[1]
+	}
+
+	return host, container
+}
+
+// deviceHostPathType returns the host path type of a host device when it's certain, i.e. for well known block devices.
+// It returns nil for any other device, as it may be a character device or a directory of devices, e.g. /dev/snd.
+func deviceHostPathType(device string) *v1.HostPathType {
+	if !BlockDevicePattern.MatchString(device) {
+		return nil
+	}
+
+	blockDev := v1.HostPathBlockDev
+	return &blockDev
+}
+
 // durationStrToSecondsInt converts duration string to *int32 in seconds
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/kubernetes/k8sutils.go#L744
 func durationStrToSecondsInt(s string) (*int32, error) {
@@ -581,6 +1275,39 @@ func getContentFromFile(file string) (string, error) {
 	return string(fileBytes), nil
 }
 
+// getSecretDataFromFileOrDir returns secret data for a secret file or a directory of secret files.
+// A single file is keyed by the secret name, whereas each regular file in a directory
+// is keyed by its file name. Directory entries are read in file name order.
+func getSecretDataFromFileOrDir(name, path string) (map[string][]byte, error) {
+	if fi, err := os.Stat(path); err != nil || !fi.IsDir() {
+		content, err := getContentFromFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]byte{name: []byte(content)}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string][]byte{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		content, err := getContentFromFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		data[entry.Name()] = []byte(content)
+	}
+
+	return data, nil
+}
+
 // rfc1123
 // NOTE: only accept alphanumeric chars (specifically excluding dots)
 // https://kubernetes.io/docs/concepts/overview/working-with-objects/names/
@@ -592,21 +1319,485 @@ func rfc1123(s string) string {
 // rfc1123dns
 // https://kubernetes.io/docs/concepts/overview/working-with-objects/names/
 func rfc1123dns(s string) string {
-	s = rfc1123(s)
-	if len(s) > 253 {
-		return s[0:253]
-	}
-	return s
+	return truncateName(rfc1123(s), dnsNameMaxLength, false)
 }
 
 // rfc1123label
 // https://kubernetes.io/docs/concepts/overview/working-with-objects/names/
 func rfc1123label(s string) string {
-	s = rfc1123(s)
-	if len(s) > 63 {
-		return s[0:63]
+	return truncateName(rfc1123(s), labelNameMaxLength, false)
+}
+
+// truncateName shortens name to max length. When hashed is true the truncated name
+// gets a short hash suffix computed from the full name, so that two long names
+// sharing the same prefix don't collide once truncated.
+func truncateName(s string, max int, hashed bool) string {
+	if len(s) <= max {
+		return s
+	}
+
+	if !hashed {
+		return s[0:max]
+	}
+
+	sum := sha256.Sum256([]byte(s))
+	suffix := hex.EncodeToString(sum[:])[0:nameHashSuffixLength]
+	prefix := strings.TrimRight(s[0:max-nameHashSuffixLength-1], "-")
+
+	return prefix + "-" + suffix
+}
+
+// labelName returns an RFC 1123 label compliant name, shortened as per the configured long names strategy
+func (k *Kubernetes) labelName(s string) string {
+	return truncateName(rfc1123(s), labelNameMaxLength, k.Opt.LongNames == LongNamesHash)
+}
+
+// ServiceName returns the name of the K8s Service rendered for the compose project service name,
+// shortened as per the default long names strategy
+func ServiceName(name string) string {
+	return rfc1123label(name)
+}
+
+// dnsName returns an RFC 1123 DNS subdomain compliant name, shortened as per the configured long names strategy
+func (k *Kubernetes) dnsName(s string) string {
+	return truncateName(rfc1123(s), dnsNameMaxLength, k.Opt.LongNames == LongNamesHash)
+}
+
+// PrefixImageRegistry prepends registry to the image unless the image already references a registry.
+// Image reference is deemed to contain a registry when its first path component is a host name,
+// i.e. it contains a "." or ":" or is "localhost", e.g. gcr.io/foo/bar or localhost:5000/bar.
+func PrefixImageRegistry(image, registry string) string {
+	registry = strings.TrimSuffix(strings.TrimSpace(registry), "/")
+	if registry == "" || image == "" {
+		return image
+	}
+
+	if parts := strings.SplitN(image, "/", 2); len(parts) == 2 {
+		if strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost" {
+			return image
+		}
+	}
+
+	return registry + "/" + image
+}
+
+// SplitImageReference splits an image reference into repository, tag and digest, e.g. `localhost:5000/app:1.0@sha256:abc`
+// into `localhost:5000/app`, `1.0` and `sha256:abc`. Tag and digest are empty when not specified.
+func SplitImageReference(image string) (repo, tag, digest string) {
+	repo = image
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo, digest = repo[:i], repo[i+1:]
+	}
+
+	// tag separator must be searched for after the last path component, as registry host may specify a port
+	slash := strings.LastIndex(repo, "/")
+	if i := strings.LastIndex(repo[slash+1:], ":"); i >= 0 {
+		repo, tag = repo[:slash+1+i], repo[slash+1+i+1:]
+	}
+
+	return repo, tag, digest
+}
+
+// ImageNameTransformer maps an image name onto the one used in build artifacts and rendered K8s manifests
+type ImageNameTransformer func(image string) string
+
+// invalidImageNameCharsRegex matches characters not allowed in a lowercased image reference
+var invalidImageNameCharsRegex = regexp.MustCompile("[^a-z0-9._/:-]+")
+
+// NormalizeImageName lowercases image repository and replaces characters invalid in image references with "-",
+// e.g. images named after build context directories. Image tag and digest are left untouched.
+func NormalizeImageName(image string) string {
+	if image == "" {
+		return image
+	}
+
+	repo, tag, digest := SplitImageReference(image)
+
+	components := strings.Split(invalidImageNameCharsRegex.ReplaceAllString(strings.ToLower(repo), "-"), "/")
+	for i, c := range components {
+		components[i] = strings.Trim(c, "-")
+	}
+
+	image = strings.Join(components, "/")
+	if tag != "" {
+		image += ":" + tag
+	}
+	if digest != "" {
+		image += "@" + digest
+	}
+
+	return image
+}
+
+// markDisabled annotates objects of a disabled service as disabled and makes sure its workloads don't run any pods.
+// Deployments and StatefulSets are scaled down to 0 replicas, Jobs are suspended and DaemonSets get a node selector
+// matching no nodes. Horizontal pod autoscalers are dropped as they would scale the workloads back up, and bare Pods
+// are dropped as they can't be stopped.
+func (k *Kubernetes) markDisabled(service string, objects []runtime.Object) []runtime.Object {
+	var zero int32
+	suspend := true
+	marked := []runtime.Object{}
+
+	for _, obj := range objects {
+		switch t := obj.(type) {
+		case *autoscalingv2beta2.HorizontalPodAutoscaler:
+			continue
+		case *v1.Pod:
+			k.warn(service, "disabled", log.Fields{
+				"project-service": service,
+				"pod":             t.Name,
+			}, "Skipping Pod of a disabled service, bare pods can't be scaled down")
+			continue
+		case *v1apps.Deployment:
+			t.Spec.Replicas = &zero
+		case *v1apps.StatefulSet:
+			t.Spec.Replicas = &zero
+		case *v1batch.Job:
+			t.Spec.Suspend = &suspend
+		case *v1apps.DaemonSet:
+			if t.Spec.Template.Spec.NodeSelector == nil {
+				t.Spec.Template.Spec.NodeSelector = map[string]string{}
+			}
+			t.Spec.Template.Spec.NodeSelector[DisabledAnnotation] = "true"
+		}
+
+		if o, ok := obj.(meta.Object); ok {
+			annotations := o.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[DisabledAnnotation] = "true"
+			o.SetAnnotations(annotations)
+		}
+
+		marked = append(marked, obj)
+	}
+
+	return marked
+}
+
+// setDefaultNamespace sets namespace on all objects which don't specify one
+func setDefaultNamespace(objects []runtime.Object, namespace string) {
+	for _, obj := range objects {
+		if o, ok := obj.(meta.Object); ok && o.GetNamespace() == "" {
+			o.SetNamespace(namespace)
+		}
+	}
+}
+
+// stampSpecHash annotates workload objects with a sha256 hash of their spec.
+// The annotation lives in object metadata so it never contributes to the hash itself.
+func stampSpecHash(objects []runtime.Object) error {
+	for _, obj := range objects {
+		var spec interface{}
+		var objectMeta *meta.ObjectMeta
+
+		switch t := obj.(type) {
+		case *v1apps.Deployment:
+			spec, objectMeta = t.Spec, &t.ObjectMeta
+		case *v1apps.StatefulSet:
+			spec, objectMeta = t.Spec, &t.ObjectMeta
+		case *v1apps.DaemonSet:
+			spec, objectMeta = t.Spec, &t.ObjectMeta
+		case *v1batch.Job:
+			spec, objectMeta = t.Spec, &t.ObjectMeta
+		default:
+			continue
+		}
+
+		// json encoding is deterministic as map keys get sorted
+		data, err := json.Marshal(spec)
+		if err != nil {
+			return err
+		}
+
+		if objectMeta.Annotations == nil {
+			objectMeta.Annotations = map[string]string{}
+		}
+		sum := sha256.Sum256(data)
+		objectMeta.Annotations[SpecHashAnnotation] = hex.EncodeToString(sum[:])
+	}
+
+	return nil
+}
+
+// RenderedKinds lists kinds of objects the converter renders
+var RenderedKinds = []string{
+	"ConfigMap", "DaemonSet", "Deployment", "ExternalSecret", "HorizontalPodAutoscaler", "Ingress", "Job",
+	"NetworkPolicy", "PersistentVolumeClaim", "Pod", "Role", "RoleBinding", "Secret", "Service",
+	"ServiceAccount", "StatefulSet",
+}
+
+// KindOrder lists kinds in the order their objects are written, so that `kubectl apply -f`
+// creates objects before the workloads depending on them. Other kinds are written last.
+var KindOrder = []string{
+	"Namespace", "NetworkPolicy", "ServiceAccount", "Role", "RoleBinding", "Secret", "ExternalSecret",
+	"ConfigMap", "PersistentVolumeClaim", "Service", "Deployment", "StatefulSet", "DaemonSet", "Job", "Pod",
+	"HorizontalPodAutoscaler", "Ingress",
+}
+
+// kindPriority returns the position of a kind in KindOrder
+func kindPriority(kind string) int {
+	for i, k := range KindOrder {
+		if k == kind {
+			return i
+		}
+	}
+	return len(KindOrder)
+}
+
+// sortObjects returns a copy of objects sorted by kind priority, then by kind and name
+func sortObjects(objects []runtime.Object) []runtime.Object {
+	sorted := append([]runtime.Object{}, objects...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, mi := objectMetas(sorted[i])
+		tj, mj := objectMetas(sorted[j])
+
+		if pi, pj := kindPriority(ti.Kind), kindPriority(tj.Kind); pi != pj {
+			return pi < pj
+		}
+		if ti.Kind != tj.Kind {
+			return ti.Kind < tj.Kind
+		}
+		return mi.Name < mj.Name
+	})
+
+	return sorted
+}
+
+// filterKinds returns objects of the included kinds, or of all kinds when none are included, minus
+// objects of the excluded kinds. Kind names are case insensitive and must be one of RenderedKinds.
+func filterKinds(objects []runtime.Object, include, exclude []string) ([]runtime.Object, error) {
+	included, err := canonicalKinds(include)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded, err := canonicalKinds(exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []runtime.Object{}
+	for _, obj := range objects {
+		kind := objectKind(obj)
+		if (len(included) > 0 && !contains(included, kind)) || contains(excluded, kind) {
+			continue
+		}
+		filtered = append(filtered, obj)
+	}
+
+	return filtered, nil
+}
+
+// canonicalKinds maps case insensitive kind names onto RenderedKinds, failing on unknown kinds
+func canonicalKinds(kinds []string) ([]string, error) {
+	out := []string{}
+
+	for _, kind := range kinds {
+		found := false
+		for _, known := range RenderedKinds {
+			if strings.EqualFold(strings.TrimSpace(kind), known) {
+				out = append(out, known)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown resource kind %q, use one of: %s", kind, strings.Join(RenderedKinds, ", "))
+		}
+	}
+
+	return out, nil
+}
+
+// stampConfigChecksum annotates workload pod templates with a checksum of ConfigMaps and Secrets data
+// referenced by their pods, via volumes, envFrom or env value references. Workloads aren't annotated
+// when they don't reference any rendered config data.
+func stampConfigChecksum(objects []runtime.Object) error {
+	type dataObject struct {
+		Kind       string
+		Name       string
+		Data       interface{}
+		BinaryData interface{}
+	}
+
+	// @step index rendered config data by kind and name
+	data := map[string]dataObject{}
+	for _, obj := range objects {
+		switch o := obj.(type) {
+		case *v1.ConfigMap:
+			data["ConfigMap/"+o.Name] = dataObject{"ConfigMap", o.Name, o.Data, o.BinaryData}
+		case *v1.Secret:
+			data["Secret/"+o.Name] = dataObject{"Secret", o.Name, o.Data, o.StringData}
+		}
+	}
+
+	for _, obj := range objects {
+		template := podTemplate(obj)
+		if template == nil {
+			continue
+		}
+
+		// @step collect data of the pod referenced ConfigMaps and Secrets, in kind and name order
+		refs := podConfigRefs(template.Spec)
+		referenced := []dataObject{}
+		for _, ref := range refs {
+			if d, ok := data[ref]; ok {
+				referenced = append(referenced, d)
+			}
+		}
+
+		if len(referenced) == 0 {
+			continue
+		}
+
+		// json encoding is deterministic as map keys get sorted
+		encoded, err := json.Marshal(referenced)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(encoded)
+
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[ConfigChecksumAnnotation] = hex.EncodeToString(sum[:])
+	}
+
+	return nil
+}
+
+// podConfigRefs returns sorted `<kind>/<name>` references of ConfigMaps and Secrets used by the pod
+// in volumes, containers envFrom and env value references
+func podConfigRefs(spec v1.PodSpec) []string {
+	refs := []string{}
+	add := func(kind, name string) {
+		if name != "" && !contains(refs, kind+"/"+name) {
+			refs = append(refs, kind+"/"+name)
+		}
+	}
+
+	for _, vol := range spec.Volumes {
+		if vol.ConfigMap != nil {
+			add("ConfigMap", vol.ConfigMap.Name)
+		}
+		if vol.Secret != nil {
+			add("Secret", vol.Secret.SecretName)
+		}
+		if vol.Projected != nil {
+			for _, src := range vol.Projected.Sources {
+				if src.ConfigMap != nil {
+					add("ConfigMap", src.ConfigMap.Name)
+				}
+				if src.Secret != nil {
+					add("Secret", src.Secret.Name)
+				}
+			}
+		}
+	}
+
+	containers := append(append([]v1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, c := range containers {
+		for _, from := range c.EnvFrom {
+			if from.ConfigMapRef != nil {
+				add("ConfigMap", from.ConfigMapRef.Name)
+			}
+			if from.SecretRef != nil {
+				add("Secret", from.SecretRef.Name)
+			}
+		}
+
+		for _, env := range c.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				add("ConfigMap", env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				add("Secret", env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+
+	sort.Strings(refs)
+	return refs
+}
+
+// objectDataSize returns the size of ConfigMap or Secret data along with the object kind/name.
+// Size of other objects is always 0.
+func objectDataSize(obj runtime.Object) (int, string) {
+	size := 0
+
+	switch o := obj.(type) {
+	case *v1.ConfigMap:
+		for _, v := range o.Data {
+			size += len(v)
+		}
+		for _, v := range o.BinaryData {
+			size += len(v)
+		}
+		return size, "configmap/" + o.Name
+	case *v1.Secret:
+		for _, v := range o.Data {
+			size += len(v)
+		}
+		for _, v := range o.StringData {
+			size += len(v)
+		}
+		return size, "secret/" + o.Name
+	}
+
+	return size, ""
+}
+
+// checkSelectors returns an error listing workloads whose label selector doesn't match their pod template labels,
+// as K8s rejects such workloads
+func checkSelectors(objects []runtime.Object) error {
+	mismatched := []string{}
+
+	for _, obj := range objects {
+		var (
+			name     string
+			selector *meta.LabelSelector
+			podMeta  meta.ObjectMeta
+		)
+
+		switch o := obj.(type) {
+		case *v1apps.Deployment:
+			name, selector, podMeta = "deployment/"+o.Name, o.Spec.Selector, o.Spec.Template.ObjectMeta
+		case *v1apps.StatefulSet:
+			name, selector, podMeta = "statefulset/"+o.Name, o.Spec.Selector, o.Spec.Template.ObjectMeta
+		case *v1apps.DaemonSet:
+			name, selector, podMeta = "daemonset/"+o.Name, o.Spec.Selector, o.Spec.Template.ObjectMeta
+		default:
+			continue
+		}
+
+		if selector == nil {
+			continue
+		}
+
+		s, err := meta.LabelSelectorAsSelector(selector)
+		if err != nil || !s.Matches(labels.Set(podMeta.Labels)) {
+			mismatched = append(mismatched, name)
+		}
+	}
+
+	if len(mismatched) > 0 {
+		return fmt.Errorf("workload selectors don't match their pod template labels: %s", strings.Join(mismatched, ", "))
 	}
-	return s
+
+	return nil
+}
+
+// mutableImageTag returns true when image isn't pinned to a digest and is either untagged or uses the "latest" tag
+func mutableImageTag(image string) bool {
+	_, tag, digest := SplitImageReference(image)
+	return digest == "" && (tag == "" || tag == "latest")
 }
 
 // formatFileName format file name
@@ -639,6 +1830,37 @@ func configAllLabels(projectService ProjectService) map[string]string {
 	return base
 }
 
+// ReservedLabelPrefixes prefix labels managed by the converter, which aren't propagated from compose deploy labels
+var ReservedLabelPrefixes = []string{"tako.appvia.io/", "io.kev"}
+
+// configPodLabels creates pod template labels, including compose deploy labels when requested.
+// Deploy labels never override labels set by the converter, e.g. the selector label.
+func configPodLabels(projectService ProjectService, withDeployLabels bool) map[string]string {
+	labels := configLabelsWithNetwork(projectService)
+	if !withDeployLabels || projectService.Deploy == nil {
+		return labels
+	}
+
+	for key, val := range projectService.Deploy.Labels {
+		if _, ok := labels[key]; ok || reservedLabel(key) {
+			continue
+		}
+		labels[key] = val
+	}
+
+	return labels
+}
+
+// reservedLabel tells whether the label key is managed by the converter
+func reservedLabel(key string) bool {
+	for _, prefix := range ReservedLabelPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // configAnnotations creates annotations to be used where they are required,
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/utils.go#L152
 func configAnnotations(src ...map[string]string) map[string]string {
@@ -662,9 +1884,13 @@ func parseIngressPath(url string) (string, string) {
 	return url, ""
 }
 
-// getComposeFileDir returns compose file directory
+// getComposeFileDir returns compose file directory, or the current directory when there are no input files
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/utils.go#L233
 func getComposeFileDir(inputFiles []string) (string, error) {
+	if len(inputFiles) == 0 {
+		return os.Getwd()
+	}
+
 	// This assumes all the docker-compose files are in the same directory
 	inputFile := inputFiles[0]
 	if strings.Index(inputFile, "/") != 0 {
@@ -678,6 +1904,16 @@ func getComposeFileDir(inputFiles []string) (string, error) {
 	return filepath.Dir(inputFile), nil
 }
 
+// composeFileDir returns the base directory relative paths referenced by the compose project are resolved against.
+// That's the configured working directory, or the compose file directory, falling back to the current directory.
+func (k *Kubernetes) composeFileDir() (string, error) {
+	if k.Opt.WorkingDir != "" {
+		return filepath.Abs(k.Opt.WorkingDir)
+	}
+
+	return getComposeFileDir(k.Opt.InputFiles)
+}
+
 // createOutFile creates the file to write to if --out is specified
 // @orig: https://github.com/kubernetes/kompose/blob/master/pkg/transformer/utils.go#L45
 func createOutFile(out string) (*os.File, error) {
@@ -705,13 +1941,18 @@ func configLabelsWithNetwork(projectService ProjectService) map[string]string {
 		labels[NetworkLabel+"/"+n] = "true"
 	}
 
+	if len(projectService.Networks) > 0 && !projectService.networkPolicyEnabled() {
+		labels[NetworkPolicyExemptLabel] = "true"
+	}
+
 	return labels
 }
 
-// findByName selects compose project service by name
+// findByName selects compose project service by its normalised name, shortened as per either long names strategy
 func findByName(projectServices composego.Services, name string) *composego.ServiceConfig {
 	for _, ps := range projectServices {
-		if rfc1123dns(ps.Name) == name {
+		normalised := rfc1123(ps.Name)
+		if truncateName(normalised, dnsNameMaxLength, false) == name || truncateName(normalised, dnsNameMaxLength, true) == name {
 			return &ps
 		}
 	}
@@ -999,6 +2240,33 @@ func contains(strs []string, s string) bool {
 	return i < len(strs) && strs[i] == s
 }
 
+// excludedService tells whether the service name matches any of the exclusions. Exclusions containing
+// wildcards are treated as glob patterns, e.g. worker-*, others must match the service name exactly.
+func excludedService(exclusions []string, name string) bool {
+	for _, exclusion := range exclusions {
+		if !strings.ContainsAny(exclusion, "*?[") {
+			if exclusion == name {
+				return true
+			}
+			continue
+		}
+
+		matched, err := path.Match(exclusion, name)
+		if err != nil {
+			log.WarnWithFields(log.Fields{
+				"pattern": exclusion,
+			}, "Invalid service exclusion pattern will be ignored")
+			continue
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
 // ToUnstructured converts runtime.Object to unstructured map[string]interface{}
 func ToUnstructured(o runtime.Object) (map[string]interface{}, error) {
 	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
@@ -17,17 +17,29 @@
 package kubernetes
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	composego "github.com/compose-spec/compose-go/types"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
 	v1apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
 )
 
 var _ = Describe("Utils", func() {
@@ -92,6 +104,450 @@ var _ = Describe("Utils", func() {
 		})
 	})
 
+	Describe("bundleConfigMap", func() {
+		objects := []runtime.Object{
+			&v1apps.Deployment{
+				TypeMeta: meta.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: meta.ObjectMeta{
+					Name: "web",
+				},
+			},
+			&v1.Service{
+				TypeMeta: meta.TypeMeta{
+					Kind:       "Service",
+					APIVersion: "v1",
+				},
+				ObjectMeta: meta.ObjectMeta{
+					Name: "web",
+				},
+			},
+		}
+
+		It("packs each rendered object under its manifest file name", func() {
+			cm, err := bundleConfigMap("manifests", objects, nil, false, 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cm.Kind).To(Equal("ConfigMap"))
+			Expect(cm.Name).To(Equal("manifests"))
+			Expect(cm.Data).To(HaveLen(len(objects)))
+			Expect(cm.Data).To(HaveKey("web-deployment.yaml"))
+			Expect(cm.Data).To(HaveKey("web-service.yaml"))
+			Expect(cm.Data["web-service.yaml"]).To(ContainSubstring("kind: Service"))
+		})
+	})
+
+	Describe("cleanOutputDir", func() {
+		var (
+			dir      string
+			rendered map[string][]byte
+		)
+
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "tako-clean-output")
+			Expect(err).NotTo(HaveOccurred())
+
+			rendered = map[string][]byte{}
+
+			manifests := map[string]string{
+				"web-deployment.yaml": "kind: Deployment\nmetadata:\n  name: web\n  labels:\n    service: web\n",
+				"db-deployment.yaml":  "kind: Deployment\nmetadata:\n  name: db\n  labels:\n    service: db\n",
+			}
+			for name, content := range manifests {
+				Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(dir)
+		})
+
+		It("preserves manifests of unmanaged services and removes the rest", func() {
+			Expect(cleanOutputDir(dir, []string{"db"}, rendered)).To(Succeed())
+
+			Expect(filepath.Join(dir, "db-deployment.yaml")).To(BeAnExistingFile())
+			Expect(filepath.Join(dir, "web-deployment.yaml")).NotTo(BeAnExistingFile())
+			Expect(rendered).To(HaveKey(filepath.Join(dir, "db-deployment.yaml")))
+		})
+
+		It("removes the whole directory when no services are preserved", func() {
+			Expect(cleanOutputDir(dir, nil, rendered)).To(Succeed())
+			Expect(dir).NotTo(BeADirectory())
+		})
+	})
+
+	Describe("PrintList", func() {
+		var (
+			dir      string
+			rendered map[string][]byte
+		)
+
+		objects := []runtime.Object{
+			&v1apps.Deployment{
+				TypeMeta: meta.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: meta.ObjectMeta{
+					Name:   "web",
+					Labels: map[string]string{Selector: "web"},
+				},
+			},
+			&v1.Service{
+				TypeMeta: meta.TypeMeta{
+					Kind:       "Service",
+					APIVersion: "v1",
+				},
+				ObjectMeta: meta.ObjectMeta{
+					Name:   "web",
+					Labels: map[string]string{Selector: "web"},
+				},
+			},
+			&v1.ConfigMap{
+				TypeMeta: meta.TypeMeta{
+					Kind:       "ConfigMap",
+					APIVersion: "v1",
+				},
+				ObjectMeta: meta.ObjectMeta{
+					Name: "shared",
+				},
+			},
+		}
+
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "tako-print-list")
+			Expect(err).NotTo(HaveOccurred())
+
+			rendered = map[string][]byte{}
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(dir)
+		})
+
+		When("output to stdout is requested", func() {
+			It("doesn't write any files, nor kustomization", func() {
+				outFile := filepath.Join(dir, "out", "k8s.yaml")
+				opt := ConvertOptions{OutFile: outFile, ToStdout: true, GenerateKustomization: true}
+				Expect(PrintList(objects, opt, nil, rendered)).To(Succeed())
+
+				Expect(filepath.Join(dir, "out")).NotTo(BeADirectory())
+				Expect(rendered).To(BeEmpty())
+			})
+		})
+
+		When("manifests index is requested", func() {
+			It("writes an index listing all generated files grouped by service and kind", func() {
+				opt := ConvertOptions{OutFile: dir, GenerateIndex: true}
+				Expect(PrintList(objects, opt, nil, rendered)).To(Succeed())
+
+				indexFile := filepath.Join(dir, ManifestIndexFileName)
+				Expect(indexFile).To(BeAnExistingFile())
+
+				Expect(rendered).To(HaveLen(len(objects) + 1))
+
+				index, err := os.ReadFile(indexFile)
+				Expect(err).NotTo(HaveOccurred())
+
+				for file := range rendered {
+					if file == indexFile {
+						continue
+					}
+					Expect(string(index)).To(ContainSubstring(filepath.Base(file)))
+				}
+
+				Expect(string(index)).To(ContainSubstring("## web\n\n### Deployment\n\n- [web-deployment.yaml](web-deployment.yaml) - Deployment `web`"))
+				Expect(string(index)).To(ContainSubstring("## " + ManifestIndexOtherGroup + "\n\n### ConfigMap\n"))
+			})
+		})
+
+		When("kustomization is requested", func() {
+			It("writes a kustomization listing all rendered manifests", func() {
+				opt := ConvertOptions{OutFile: dir, GenerateIndex: true, GenerateKustomization: true}
+				Expect(PrintList(objects, opt, nil, rendered)).To(Succeed())
+
+				kustomizationFile := filepath.Join(dir, KustomizationFileName)
+				Expect(kustomizationFile).To(BeAnExistingFile())
+
+				data, err := os.ReadFile(kustomizationFile)
+				Expect(err).NotTo(HaveOccurred())
+
+				var k struct {
+					Kind      string   `yaml:"kind"`
+					Resources []string `yaml:"resources"`
+				}
+				Expect(yaml.Unmarshal(data, &k)).To(Succeed())
+				Expect(k.Kind).To(Equal("Kustomization"))
+				Expect(k.Resources).To(HaveLen(len(objects)))
+				Expect(k.Resources).To(ContainElement("web-deployment.yaml"))
+				Expect(k.Resources).NotTo(ContainElement(ManifestIndexFileName))
+			})
+
+			It("lists the single manifests file when rendering to a single file", func() {
+				opt := ConvertOptions{OutFile: filepath.Join(dir, "k8s.yaml"), GenerateKustomization: true}
+				Expect(PrintList(objects, opt, nil, rendered)).To(Succeed())
+
+				data, err := os.ReadFile(filepath.Join(dir, KustomizationFileName))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(ContainSubstring("resources:\n    - k8s.yaml\n"))
+			})
+		})
+
+		When("index file names are requested", func() {
+			It("prefixes file names with a zero padded index in kind priority then name order", func() {
+				mixed := append([]runtime.Object{
+					&v1.Secret{
+						TypeMeta:   meta.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+						ObjectMeta: meta.ObjectMeta{Name: "web-env"},
+					},
+					&v1.ConfigMap{
+						TypeMeta:   meta.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+						ObjectMeta: meta.ObjectMeta{Name: "app"},
+					},
+				}, objects...)
+
+				opt := ConvertOptions{OutFile: dir, IndexFileNames: true}
+				Expect(PrintList(mixed, opt, nil, rendered)).To(Succeed())
+
+				files := []string{}
+				for file := range rendered {
+					files = append(files, filepath.Base(file))
+				}
+				sort.Strings(files)
+
+				Expect(files).To(Equal([]string{
+					"001-web-env-secret.yaml",
+					"002-app-configmap.yaml",
+					"003-shared-configmap.yaml",
+					"004-web-service.yaml",
+					"005-web-deployment.yaml",
+				}))
+			})
+		})
+
+		When("rendering to a single file", func() {
+			It("lists objects in kind priority then name order", func() {
+				file := filepath.Join(dir, "k8s.yaml")
+				Expect(PrintList(objects, ConvertOptions{OutFile: file}, nil, rendered)).To(Succeed())
+
+				data, err := os.ReadFile(file)
+				Expect(err).NotTo(HaveOccurred())
+
+				configMap := strings.Index(string(data), "kind: ConfigMap")
+				service := strings.Index(string(data), "kind: Service")
+				deployment := strings.Index(string(data), "kind: Deployment")
+				Expect(configMap).To(BeNumerically("<", service))
+				Expect(service).To(BeNumerically("<", deployment))
+			})
+		})
+
+		When("nested output layout is requested", func() {
+			It("writes manifests into namespace and kind directories", func() {
+				opt := ConvertOptions{OutFile: dir, NestedOutputLayout: true}
+				Expect(PrintList(objects, opt, nil, rendered)).To(Succeed())
+
+				Expect(filepath.Join(dir, NestedLayoutDefaultNamespace, "deployment", "web.yaml")).To(BeAnExistingFile())
+				Expect(filepath.Join(dir, NestedLayoutDefaultNamespace, "service", "web.yaml")).To(BeAnExistingFile())
+				Expect(filepath.Join(dir, "web-deployment.yaml")).NotTo(BeAnExistingFile())
+			})
+		})
+
+		When("JSON output is requested with custom indentation", func() {
+			It("indents JSON accordingly", func() {
+				file := filepath.Join(dir, "k8s.json")
+				opt := ConvertOptions{OutFile: file, GenerateJSON: true, YAMLIndent: 4}
+				Expect(PrintList(objects, opt, nil, rendered)).To(Succeed())
+
+				data, err := os.ReadFile(file)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(HavePrefix("{\n    \"apiVersion\": \"v1\","))
+			})
+		})
+
+		When("JSON lines output is requested", func() {
+			It("writes one compact JSON object per line", func() {
+				file := filepath.Join(dir, "k8s.json")
+				opt := ConvertOptions{OutFile: file, JSONLines: true}
+				Expect(PrintList(objects, opt, nil, rendered)).To(Succeed())
+
+				data, err := os.ReadFile(file)
+				Expect(err).NotTo(HaveOccurred())
+
+				lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+				Expect(lines).To(HaveLen(len(objects)))
+				for _, line := range lines {
+					obj := map[string]interface{}{}
+					Expect(json.Unmarshal([]byte(line), &obj)).To(Succeed())
+					Expect(obj).To(HaveKey("kind"))
+				}
+				Expect(lines[0]).To(ContainSubstring(`"kind":"ConfigMap"`))
+			})
+		})
+
+		When("archive output is requested", func() {
+			It("packs every rendered manifest into a gzip compressed tarball", func() {
+				archive := filepath.Join(dir, "manifests.tar.gz")
+				opt := ConvertOptions{OutputArchive: archive}
+				Expect(PrintList(objects, opt, nil, rendered)).To(Succeed())
+				Expect(rendered).To(HaveKey(archive))
+
+				f, err := os.Open(archive)
+				Expect(err).NotTo(HaveOccurred())
+				defer f.Close()
+
+				gz, err := gzip.NewReader(f)
+				Expect(err).NotTo(HaveOccurred())
+
+				decoder := scheme.Codecs.UniversalDeserializer()
+				files := []string{}
+				decoded := []runtime.Object{}
+
+				tr := tar.NewReader(gz)
+				for {
+					header, err := tr.Next()
+					if err == io.EOF {
+						break
+					}
+					Expect(err).NotTo(HaveOccurred())
+					files = append(files, header.Name)
+
+					data, err := io.ReadAll(tr)
+					Expect(err).NotTo(HaveOccurred())
+
+					obj, _, err := decoder.Decode(data, nil, nil)
+					Expect(err).NotTo(HaveOccurred())
+					decoded = append(decoded, obj)
+				}
+
+				Expect(files).To(Equal([]string{
+					"shared-configmap.yaml",
+					"web-deployment.yaml",
+					"web-service.yaml",
+				}))
+				Expect(decoded).To(ConsistOf(objects))
+			})
+
+			It("carries manifests of preserved services over from the previous archive", func() {
+				archive := filepath.Join(dir, "manifests.tar.gz")
+				Expect(PrintList(objects, ConvertOptions{OutputArchive: archive}, nil, rendered)).To(Succeed())
+
+				opt := ConvertOptions{OutputArchive: archive, PreserveServices: []string{"web"}}
+				Expect(PrintList(objects[2:], opt, nil, map[string][]byte{})).To(Succeed())
+
+				f, err := os.Open(archive)
+				Expect(err).NotTo(HaveOccurred())
+				defer f.Close()
+
+				gz, err := gzip.NewReader(f)
+				Expect(err).NotTo(HaveOccurred())
+
+				files := []string{}
+				tr := tar.NewReader(gz)
+				for {
+					header, err := tr.Next()
+					if err == io.EOF {
+						break
+					}
+					Expect(err).NotTo(HaveOccurred())
+					files = append(files, header.Name)
+				}
+
+				Expect(files).To(Equal([]string{
+					"shared-configmap.yaml",
+					"web-deployment.yaml",
+					"web-service.yaml",
+				}))
+			})
+		})
+
+		When("manifests index isn't requested", func() {
+			It("doesn't write an index", func() {
+				opt := ConvertOptions{OutFile: dir}
+				Expect(PrintList(objects, opt, nil, rendered)).To(Succeed())
+				Expect(filepath.Join(dir, ManifestIndexFileName)).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("PrintListTo", func() {
+		objects := []runtime.Object{
+			&v1.Service{
+				TypeMeta:   meta.TypeMeta{Kind: "Service", APIVersion: "v1"},
+				ObjectMeta: meta.ObjectMeta{Name: "web"},
+			},
+			&v1.ConfigMap{
+				TypeMeta:   meta.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+				ObjectMeta: meta.ObjectMeta{Name: "shared"},
+			},
+		}
+
+		It("writes objects as a list to the writer", func() {
+			var b bytes.Buffer
+			Expect(PrintListTo(&b, objects, ConvertOptions{}, nil)).To(Succeed())
+
+			var list struct {
+				Kind  string `yaml:"kind"`
+				Items []struct {
+					Kind     string `yaml:"kind"`
+					Metadata struct {
+						Name string `yaml:"name"`
+					} `yaml:"metadata"`
+				} `yaml:"items"`
+			}
+			Expect(yaml.Unmarshal(b.Bytes(), &list)).To(Succeed())
+
+			Expect(list.Kind).To(Equal("List"))
+			Expect(list.Items).To(HaveLen(2))
+			Expect(list.Items[0].Kind).To(Equal("ConfigMap"))
+			Expect(list.Items[0].Metadata.Name).To(Equal("shared"))
+			Expect(list.Items[1].Kind).To(Equal("Service"))
+			Expect(list.Items[1].Metadata.Name).To(Equal("web"))
+		})
+
+		It("writes JSON lines to the writer when requested", func() {
+			var b bytes.Buffer
+			Expect(PrintListTo(&b, objects, ConvertOptions{JSONLines: true}, nil)).To(Succeed())
+
+			lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(ContainSubstring(`"kind":"ConfigMap"`))
+			Expect(lines[1]).To(ContainSubstring(`"kind":"Service"`))
+		})
+	})
+
+	Describe("SplitImageReference", func() {
+		It("splits image reference into repository, tag and digest", func() {
+			repo, tag, digest := SplitImageReference("localhost:5000/org/app:1.0@sha256:0123456789abcdef")
+			Expect(repo).To(Equal("localhost:5000/org/app"))
+			Expect(tag).To(Equal("1.0"))
+			Expect(digest).To(Equal("sha256:0123456789abcdef"))
+		})
+
+		It("doesn't mistake registry port for a tag", func() {
+			repo, tag, digest := SplitImageReference("localhost:5000/app")
+			Expect(repo).To(Equal("localhost:5000/app"))
+			Expect(tag).To(BeEmpty())
+			Expect(digest).To(BeEmpty())
+		})
+	})
+
+	Describe("mutableImageTag", func() {
+		It("reports untagged and latest images as mutable", func() {
+			Expect(mutableImageTag("nginx")).To(BeTrue())
+			Expect(mutableImageTag("nginx:latest")).To(BeTrue())
+			Expect(mutableImageTag("localhost:5000/nginx")).To(BeTrue())
+		})
+
+		It("reports tagged and digest pinned images as immutable", func() {
+			Expect(mutableImageTag("nginx:1.25")).To(BeFalse())
+			Expect(mutableImageTag("localhost:5000/nginx:1.25")).To(BeFalse())
+			Expect(mutableImageTag("nginx@sha256:0123456789abcdef")).To(BeFalse())
+		})
+	})
+
 	Describe("getImagePullPolicy", func() {
 		s := "db"
 
@@ -208,6 +664,30 @@ var _ = Describe("Utils", func() {
 		})
 	})
 
+	Describe("truncateName", func() {
+
+		When("name is within the max length", func() {
+			It("returns the name unchanged", func() {
+				Expect(truncateName("my-service", 63, true)).To(Equal("my-service"))
+			})
+		})
+
+		When("name exceeds the max length", func() {
+			name := strings.Repeat("a", 70)
+
+			It("truncates the name when hash suffix isn't requested", func() {
+				Expect(truncateName(name, 63, false)).To(Equal(strings.Repeat("a", 63)))
+			})
+
+			It("truncates the name and appends a hash suffix when requested", func() {
+				out := truncateName(name, 63, true)
+				Expect(out).To(HaveLen(63))
+				Expect(out).To(HavePrefix(strings.Repeat("a", 54) + "-"))
+				Expect(truncateName(name, 63, true)).To(Equal(out))
+			})
+		})
+	})
+
 	Describe("configLabelsWithNetwork", func() {
 		svcName := "db"
 		networkNameA := "mynetA"
@@ -573,4 +1053,122 @@ var _ = Describe("Utils", func() {
 		})
 
 	})
+
+	Describe("NormalizeImageName", func() {
+		It("lowercases image repository and replaces invalid characters", func() {
+			Expect(NormalizeImageName("MyService")).To(Equal("myservice"))
+			Expect(NormalizeImageName("Quay.io/MyOrg/My Service")).To(Equal("quay.io/myorg/my-service"))
+		})
+
+		It("keeps registry port, tag and digest untouched", func() {
+			Expect(NormalizeImageName("localhost:5000/MyApp:V1.0")).To(Equal("localhost:5000/myapp:V1.0"))
+			Expect(NormalizeImageName("MyApp@sha256:abc")).To(Equal("myapp@sha256:abc"))
+		})
+
+		It("leaves valid image names untouched", func() {
+			Expect(NormalizeImageName("gcr.io/foo/bar_baz-1.2:latest")).To(Equal("gcr.io/foo/bar_baz-1.2:latest"))
+		})
+	})
+
+	Describe("checkSelectors", func() {
+		var deployment *v1apps.Deployment
+
+		BeforeEach(func() {
+			deployment = &v1apps.Deployment{
+				ObjectMeta: meta.ObjectMeta{Name: "web"},
+				Spec: v1apps.DeploymentSpec{
+					Selector: &meta.LabelSelector{
+						MatchLabels: configLabels("web"),
+					},
+					Template: v1.PodTemplateSpec{
+						ObjectMeta: meta.ObjectMeta{
+							Labels: map[string]string{Selector: "web", NetworkLabel + "/default": "true"},
+						},
+					},
+				},
+			}
+		})
+
+		It("accepts workloads selecting a subset of their pod template labels", func() {
+			Expect(checkSelectors([]runtime.Object{deployment})).To(Succeed())
+		})
+
+		It("returns an error listing workloads with selector not matching their pod template labels", func() {
+			deployment.Spec.Selector.MatchLabels = configLabels("api")
+
+			err := checkSelectors([]runtime.Object{deployment, &v1.Service{}})
+			Expect(err).To(MatchError("workload selectors don't match their pod template labels: deployment/web"))
+		})
+	})
+
+	Describe("podConfigRefs", func() {
+		It("returns sorted references of ConfigMaps and Secrets used by the pod", func() {
+			spec := v1.PodSpec{
+				Volumes: []v1.Volume{
+					{VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "token"}}},
+					{VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{
+						LocalObjectReference: v1.LocalObjectReference{Name: "app-config"},
+					}}},
+				},
+				Containers: []v1.Container{{
+					EnvFrom: []v1.EnvFromSource{{
+						SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "web-env"}},
+					}},
+					Env: []v1.EnvVar{{
+						Name: "TOKEN",
+						ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+							LocalObjectReference: v1.LocalObjectReference{Name: "token"},
+							Key:                  "token",
+						}},
+					}},
+				}},
+			}
+
+			Expect(podConfigRefs(spec)).To(Equal([]string{"ConfigMap/app-config", "Secret/token", "Secret/web-env"}))
+		})
+	})
+
+	Describe("parseCPU", func() {
+		It("parses a fractional number of CPUs into millicpu", func() {
+			Expect(parseCPU("0.5")).To(BeEquivalentTo(500))
+		})
+
+		It("parses millicpu", func() {
+			Expect(parseCPU("500m")).To(BeEquivalentTo(500))
+		})
+
+		It("parses whole CPUs", func() {
+			Expect(parseCPU("2")).To(BeEquivalentTo(2000))
+		})
+
+		It("rejects invalid values", func() {
+			_, err := parseCPU("abc")
+			Expect(err).To(MatchError(`invalid CPU value "abc", use a number of CPUs, e.g. 0.5, or millicpu, e.g. 500m`))
+		})
+
+		It("rejects negative values", func() {
+			_, err := parseCPU("-1")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("rejects quantity suffixes other than millicpu", func() {
+			_, err := parseCPU("1Gi")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("deviceHostPathType", func() {
+		It("types well known block devices", func() {
+			blockDev := v1.HostPathBlockDev
+			for _, device := range []string{"/dev/sda", "/dev/sdb1", "/dev/xvdf", "/dev/nvme0n1", "/dev/nvme0n1p2", "/dev/loop0", "/dev/mmcblk0p1", "/dev/dm-0"} {
+				Expect(deviceHostPathType(device)).To(Equal(&blockDev), device)
+			}
+		})
+
+		It("leaves other devices untyped", func() {
+			for _, device := range []string{"/dev/snd", "/dev/dri", "/dev/nvme0", "/dev/ttyUSB0", "/dev/loop-control"} {
+				Expect(deviceHostPathType(device)).To(BeNil(), device)
+			}
+		})
+	})
 })
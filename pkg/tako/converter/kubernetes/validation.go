@@ -0,0 +1,144 @@
+/**
+ * Copyright 2020 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	v1apps "k8s.io/api/apps/v1"
+	v1batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// validateObjects checks rendered objects against K8s API validation rules which would get them rejected
+// by the cluster, e.g. invalid names, labels or missing required fields. It returns all problems found,
+// each with the compose project service the object was generated for.
+func validateObjects(objects []runtime.Object) error {
+	problems := []string{}
+
+	for _, obj := range objects {
+		accessor, ok := obj.(meta.Object)
+		if !ok {
+			continue
+		}
+
+		// @step services names must be DNS labels, all other object names DNS subdomains
+		nameFn := apivalidation.NameIsDNSSubdomain
+		if _, ok := obj.(*v1.Service); ok {
+			nameFn = apivalidation.NameIsDNS1035Label
+		}
+
+		// @step all rendered kinds are namespaced, objects without a namespace get one when applied
+		errs := field.ErrorList{}
+		for _, e := range apivalidation.ValidateObjectMetaAccessor(accessor, true, nameFn, field.NewPath("metadata")) {
+			if e.Type == field.ErrorTypeRequired && e.Field == "metadata.namespace" {
+				continue
+			}
+			errs = append(errs, e)
+		}
+
+		if template := podTemplate(obj); template != nil {
+			errs = append(errs, validatePodSpec(&template.Spec, field.NewPath("spec", "template", "spec"))...)
+		} else if pod, ok := obj.(*v1.Pod); ok {
+			errs = append(errs, validatePodSpec(&pod.Spec, field.NewPath("spec"))...)
+		}
+
+		if len(errs) == 0 {
+			continue
+		}
+
+		service := accessor.GetLabels()[Selector]
+		if service == "" {
+			service = "-"
+		}
+
+		for _, e := range errs {
+			problems = append(problems, fmt.Sprintf("%s %q of service %q: %s", objectKind(obj), accessor.GetName(), service, e.Error()))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("rendered objects are invalid:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+
+	return nil
+}
+
+// validatePodSpec checks required pod spec fields and container names
+func validatePodSpec(spec *v1.PodSpec, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if len(spec.Containers) == 0 {
+		errs = append(errs, field.Required(fldPath.Child("containers"), ""))
+	}
+
+	for i, c := range spec.Containers {
+		path := fldPath.Child("containers").Index(i)
+
+		if c.Name == "" {
+			errs = append(errs, field.Required(path.Child("name"), ""))
+		} else {
+			for _, msg := range validation.IsDNS1123Label(c.Name) {
+				errs = append(errs, field.Invalid(path.Child("name"), c.Name, msg))
+			}
+		}
+
+		if strings.TrimSpace(c.Image) == "" {
+			errs = append(errs, field.Required(path.Child("image"), ""))
+		}
+	}
+
+	for i, vol := range spec.Volumes {
+		for _, msg := range validation.IsDNS1123Label(vol.Name) {
+			errs = append(errs, field.Invalid(fldPath.Child("volumes").Index(i).Child("name"), vol.Name, msg))
+		}
+	}
+
+	return errs
+}
+
+// podTemplate returns pod template of a workload object, nil for other objects
+func podTemplate(obj runtime.Object) *v1.PodTemplateSpec {
+	switch o := obj.(type) {
+	case *v1apps.Deployment:
+		return &o.Spec.Template
+	case *v1apps.StatefulSet:
+		return &o.Spec.Template
+	case *v1apps.DaemonSet:
+		return &o.Spec.Template
+	case *v1batch.Job:
+		return &o.Spec.Template
+	}
+
+	return nil
+}
+
+// objectKind returns object kind, falling back to its Go type name when type meta isn't set
+func objectKind(obj runtime.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+
+	return reflect.TypeOf(obj).Elem().Name()
+}
@@ -0,0 +1,162 @@
+/**
+ * Copyright 2020 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1apps "k8s.io/api/apps/v1"
+	v1batch "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ = Describe("Validation", func() {
+
+	Describe("validateObjects", func() {
+		var deployment *v1apps.Deployment
+
+		BeforeEach(func() {
+			deployment = &v1apps.Deployment{
+				TypeMeta: meta.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: "apps/v1",
+				},
+				ObjectMeta: meta.ObjectMeta{
+					Name:   "web",
+					Labels: configLabels("web"),
+				},
+				Spec: v1apps.DeploymentSpec{
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name:  "web",
+									Image: "nginx",
+								},
+							},
+						},
+					},
+				},
+			}
+		})
+
+		It("accepts valid objects", func() {
+			Expect(validateObjects([]runtime.Object{deployment})).To(Succeed())
+		})
+
+		When("object name is too long", func() {
+			BeforeEach(func() {
+				deployment.Name = strings.Repeat("a", 254)
+			})
+
+			It("returns a descriptive error with the offending service", func() {
+				err := validateObjects([]runtime.Object{deployment})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(`Deployment "` + deployment.Name + `" of service "web": metadata.name: Invalid value`))
+				Expect(err.Error()).To(ContainSubstring("must be no more than 253 characters"))
+			})
+		})
+
+		When("service name isn't a DNS label", func() {
+			It("returns an error", func() {
+				svc := &v1.Service{
+					ObjectMeta: meta.ObjectMeta{
+						Name:   strings.Repeat("s", 64),
+						Labels: configLabels("web"),
+					},
+				}
+
+				err := validateObjects([]runtime.Object{svc})
+				Expect(err).To(MatchError(ContainSubstring(`Service "` + svc.Name + `" of service "web": metadata.name: Invalid value`)))
+			})
+		})
+
+		When("workload container image is missing", func() {
+			BeforeEach(func() {
+				deployment.Spec.Template.Spec.Containers[0].Image = ""
+			})
+
+			It("returns a descriptive error with the offending service", func() {
+				err := validateObjects([]runtime.Object{deployment})
+				Expect(err).To(MatchError(ContainSubstring(`Deployment "web" of service "web": spec.template.spec.containers[0].image: Required value`)))
+			})
+		})
+
+		When("objects have a namespace", func() {
+			It("accepts them", func() {
+				deployment.Namespace = "ci"
+				Expect(validateObjects([]runtime.Object{deployment})).To(Succeed())
+			})
+		})
+
+		When("job container image is missing", func() {
+			It("returns a descriptive error with the offending service", func() {
+				job := &v1batch.Job{
+					TypeMeta: meta.TypeMeta{
+						Kind:       "Job",
+						APIVersion: "batch/v1",
+					},
+					ObjectMeta: deployment.ObjectMeta,
+					Spec: v1batch.JobSpec{
+						Template: *deployment.Spec.Template.DeepCopy(),
+					},
+				}
+				job.Spec.Template.Spec.Containers[0].Image = ""
+
+				err := validateObjects([]runtime.Object{job})
+				Expect(err).To(MatchError(ContainSubstring(`Job "web" of service "web": spec.template.spec.containers[0].image: Required value`)))
+			})
+		})
+
+		When("bare pod container image is missing", func() {
+			It("returns a descriptive error with the offending service", func() {
+				pod := &v1.Pod{
+					TypeMeta: meta.TypeMeta{
+						Kind:       "Pod",
+						APIVersion: "v1",
+					},
+					ObjectMeta: deployment.ObjectMeta,
+					Spec:       *deployment.Spec.Template.Spec.DeepCopy(),
+				}
+				pod.Spec.Containers[0].Image = ""
+
+				err := validateObjects([]runtime.Object{pod})
+				Expect(err).To(MatchError(ContainSubstring(`Pod "web" of service "web": spec.containers[0].image: Required value`)))
+			})
+		})
+
+		When("multiple objects are invalid", func() {
+			It("aggregates all problems", func() {
+				deployment.Spec.Template.Spec.Containers[0].Image = ""
+				cm := &v1.ConfigMap{
+					ObjectMeta: meta.ObjectMeta{
+						Name: "Invalid_Name",
+					},
+				}
+
+				err := validateObjects([]runtime.Object{deployment, cm})
+				Expect(err).To(HaveOccurred())
+				Expect(strings.Count(err.Error(), "\n  - ")).To(Equal(2))
+				Expect(err.Error()).To(ContainSubstring(`ConfigMap "Invalid_Name" of service "-"`))
+			})
+		})
+	})
+})
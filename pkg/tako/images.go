@@ -0,0 +1,83 @@
+/**
+ * Copyright 2020 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tako
+
+import (
+	"os"
+
+	"github.com/appvia/tako/pkg/tako/converter/kubernetes"
+	"github.com/appvia/tako/pkg/tako/log"
+	composego "github.com/compose-spec/compose-go/types"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ImageTags maps environment names to image tags of their services, e.g.
+//
+//	dev:
+//	  web: 1.2.0-rc1
+//	prod:
+//	  web: 1.1.0
+type ImageTags map[string]map[string]string
+
+// LoadImageTags loads environment image tags from a YAML file
+func LoadImageTags(path string) (ImageTags, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := ImageTags{}
+	if err := yaml.Unmarshal(data, &tags); err != nil {
+		return nil, errors.Wrapf(err, "invalid image tags file %s", path)
+	}
+
+	return tags, nil
+}
+
+// Apply sets image tags defined for the environment on the project services
+func (t ImageTags) Apply(env string, project *composego.Project) {
+	tags, ok := t[env]
+	if !ok || project == nil {
+		return
+	}
+
+	for i, svc := range project.Services {
+		tag, ok := tags[svc.Name]
+		if !ok || tag == "" {
+			continue
+		}
+
+		image := svc.Image
+		if image == "" {
+			image = svc.Name
+		}
+
+		project.Services[i].Image = retagImage(image, tag)
+
+		log.DebugfWithFields(log.Fields{
+			"env":     env,
+			"service": svc.Name,
+		}, "Image tag set to %q", tag)
+	}
+}
+
+// retagImage replaces tag or digest of the image with the given tag
+func retagImage(image, tag string) string {
+	repo, _, _ := kubernetes.SplitImageReference(image)
+	return repo + ":" + tag
+}
@@ -0,0 +1,99 @@
+/**
+ * Copyright 2020 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tako_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/appvia/tako/pkg/tako"
+	composego "github.com/compose-spec/compose-go/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ImageTags", func() {
+
+	Describe("Apply", func() {
+		tags := tako.ImageTags{
+			"dev":  {"db": "8.0.20-dev"},
+			"prod": {"db": "8.0.19"},
+		}
+
+		newProject := func() *composego.Project {
+			return &composego.Project{
+				Services: composego.Services{
+					{Name: "db", Image: "localhost:5000/mysql:latest"},
+					{Name: "web"},
+				},
+			}
+		}
+
+		It("sets environment specific image tags on project services", func() {
+			dev, prod := newProject(), newProject()
+			tags.Apply("dev", dev)
+			tags.Apply("prod", prod)
+
+			Expect(dev.Services[0].Image).To(Equal("localhost:5000/mysql:8.0.20-dev"))
+			Expect(prod.Services[0].Image).To(Equal("localhost:5000/mysql:8.0.19"))
+		})
+
+		It("leaves services without a tag for the environment untouched", func() {
+			p := newProject()
+			tags.Apply("dev", p)
+			Expect(p.Services[1].Image).To(BeEmpty())
+
+			p = newProject()
+			tags.Apply("uat", p)
+			Expect(p.Services[0].Image).To(Equal("localhost:5000/mysql:latest"))
+		})
+	})
+
+	Describe("rendering with image tags file", func() {
+		var (
+			composePath = "init-default/compose-yml/compose.yml"
+			wd          string
+			err         error
+		)
+
+		BeforeEach(func() {
+			wd, err = NewTempWorkingDir(composePath)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tako.InitProjectWithOptions(wd, tako.WithEnvs([]string{"dev", "prod"}))).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(wd)).To(Succeed())
+		})
+
+		It("renders different image tags for the same service in each environment", func() {
+			tagsFile := filepath.Join(wd, "image-tags.yaml")
+			Expect(os.WriteFile(tagsFile, []byte("dev:\n  db: 8.0.20-dev\nprod:\n  db: 8.0.19\n"), 0644)).To(Succeed())
+
+			Expect(tako.RenderProjectWithOptions(wd, tako.WithImageTagsFile(tagsFile))).To(Succeed())
+
+			dev, err := os.ReadFile(filepath.Join(wd, "k8s", "dev", "db-statefulset.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(dev)).To(ContainSubstring("image: mysql:8.0.20-dev"))
+
+			prod, err := os.ReadFile(filepath.Join(wd, "k8s", "prod", "db-statefulset.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(prod)).To(ContainSubstring("image: mysql:8.0.19"))
+		})
+	})
+})
@@ -163,6 +163,8 @@ func (r *InitRunner) CreateManifestAndEnvironmentOverrides(sources *Sources) err
 	}
 
 	r.manifest = NewManifest(sources)
+	r.manifest.ImageRegistry = r.config.ImageRegistry
+	r.manifest.NormalizeImageNames = r.config.NormalizeImageNames
 	r.manifest.UI = r.UI
 
 	sg := r.UI.StepGroup()
@@ -205,20 +207,53 @@ func (r *InitRunner) CreateOrUpdateSkaffoldManifest() (*SkaffoldManifest, error)
 
 	skPath := filepath.Join(r.WorkingDir, SkaffoldFileName)
 	envs := r.manifest.GetEnvironmentsNames()
+
+	skOpts := append([]SkaffoldManifestOption{WithPortForwards(composeProject)}, r.manifest.skaffoldImageOptions()...)
+	if r.config.SkaffoldKubeContexts {
+		skOpts = append(skOpts, WithKubeContextActivation())
+	}
+	if r.config.SkaffoldManifestsFormat != "" {
+		format, err := ParseManifestsFormat(r.config.SkaffoldManifestsFormat)
+		if err != nil {
+			initStepError(r.UI, sg.Add(""), initStepUpdateSkaffold, err)
+			return nil, err
+		}
+		skOpts = append(skOpts, WithManifestsFormat(format))
+	}
+	if r.config.SkaffoldTagPolicy != "" {
+		policy, err := ParseTagPolicy(r.config.SkaffoldTagPolicy)
+		if err != nil {
+			initStepError(r.UI, sg.Add(""), initStepUpdateSkaffold, err)
+			return nil, err
+		}
+		skOpts = append(skOpts, WithTagPolicy(policy))
+	}
+	if r.config.SkaffoldStatusCheckDeadlineSeconds < 0 {
+		err := fmt.Errorf("skaffold status check deadline must be a positive number of seconds, got %d", r.config.SkaffoldStatusCheckDeadlineSeconds)
+		initStepError(r.UI, sg.Add(""), initStepUpdateSkaffold, err)
+		return nil, err
+	}
+	if r.config.SkaffoldStatusCheckDisabled || r.config.SkaffoldStatusCheckDeadlineSeconds > 0 {
+		deadline := r.config.SkaffoldStatusCheckDeadlineSeconds
+		if deadline == 0 {
+			deadline = DefaultStatusCheckDeadlineSeconds
+		}
+		skOpts = append(skOpts, WithStatusCheck(!r.config.SkaffoldStatusCheckDisabled, deadline))
+	}
 	switch ManifestExistsForPath(skPath) {
 	case true:
 		updateStep := sg.Add(fmt.Sprintf("Adding deployment environments to existing Skaffold config: %s", skPath))
 		// Skaffold manifest already present - add additional profiles to it!
 		// Note: tako will skip profiles with names matching those of existing
 		// profile names defined in Skaffold to avoid profile "hijack".
-		if skManifest, err = InjectProfiles(skPath, envs, true); err != nil {
+		if skManifest, err = InjectProfiles(skPath, envs, true, skOpts...); err != nil {
 			initStepError(r.UI, updateStep, initStepUpdateSkaffold, err)
 			return nil, err
 		}
 		updateStep.Success()
 	case false:
 		createStep := sg.Add(fmt.Sprintf("Creating Skaffold config with deployment environment profiles at: %s", skPath))
-		skManifest = NewSkaffoldManifest(envs, composeProject)
+		skManifest = NewSkaffoldManifest(envs, composeProject, skOpts...)
 		createStep.Success()
 	}
 
@@ -87,6 +87,62 @@ var _ = Describe("InitRunner", func() {
 		})
 	})
 
+	Context("Skaffold tag policy", func() {
+		BeforeEach(func() {
+			workingDir = "./testdata/init-default/compose-yml"
+		})
+
+		skaffoldManifest := func(results tako.WritableResults) *tako.SkaffoldManifest {
+			for _, r := range results {
+				if sk, ok := r.WriterTo.(*tako.SkaffoldManifest); ok {
+					return sk
+				}
+			}
+			return nil
+		}
+
+		It("tags built images as per the configured policy", func() {
+			runner := tako.NewInitRunner(workingDir, tako.WithSkaffold(true), tako.WithSkaffoldTagPolicy("sha256"))
+			results, err := runner.Run()
+			Expect(err).NotTo(HaveOccurred())
+
+			sk := skaffoldManifest(results)
+			Expect(sk).NotTo(BeNil())
+			Expect(sk.Build.TagPolicy.ShaTagger).NotTo(BeNil())
+			Expect(sk.Build.TagPolicy.GitTagger).To(BeNil())
+		})
+
+		It("disables the status check when requested", func() {
+			runner := tako.NewInitRunner(workingDir, tako.WithSkaffold(true), tako.WithSkaffoldStatusCheck(false, 0))
+			results, err := runner.Run()
+			Expect(err).NotTo(HaveOccurred())
+
+			sk := skaffoldManifest(results)
+			Expect(sk).NotTo(BeNil())
+			for _, p := range sk.Profiles {
+				if p.Deploy.StatusCheck != nil {
+					Expect(*p.Deploy.StatusCheck).To(BeFalse())
+				}
+			}
+		})
+
+		It("sets the status check deadline when requested", func() {
+			runner := tako.NewInitRunner(workingDir, tako.WithSkaffold(true), tako.WithSkaffoldStatusCheck(true, 120))
+			results, err := runner.Run()
+			Expect(err).NotTo(HaveOccurred())
+
+			sk := skaffoldManifest(results)
+			Expect(sk).NotTo(BeNil())
+			Expect(sk.Profiles[0].Deploy.StatusCheckDeadlineSeconds).To(Equal(120))
+		})
+
+		It("fails on an unsupported policy", func() {
+			runner := tako.NewInitRunner(workingDir, tako.WithSkaffold(true), tako.WithSkaffoldTagPolicy("inputDigest"))
+			_, err := runner.Run()
+			Expect(err).To(MatchError(ContainSubstring(`unsupported tag policy "inputDigest"`)))
+		})
+	})
+
 	When("No alternate compose files supplied", func() {
 		Context("and without any docker-compose file in the directory", func() {
 			BeforeEach(func() {
@@ -275,5 +331,44 @@ var _ = Describe("InitRunner", func() {
 				})
 			})
 		})
+
+		When("there are project level extensions in the sources", func() {
+			BeforeEach(func() {
+				workingDir = "./testdata/init-default/compose-yaml-project-ext"
+			})
+
+			replicas := func(name string) int {
+				svc, err := env.GetService(name)
+				Expect(err).NotTo(HaveOccurred())
+
+				svcK8sConfig, err := config.ParseSvcK8sConfigFromMap(svc.Extensions, config.SkipValidation())
+				Expect(err).NotTo(HaveOccurred())
+				return svcK8sConfig.Workload.Replicas
+			}
+
+			It("writes project level values for services which don't set their own", func() {
+				Expect(replicas("db")).To(Equal(3))
+			})
+
+			It("keeps values set by the service", func() {
+				Expect(replicas("cache")).To(Equal(5))
+			})
+		})
+
+		When("service extension is referenced from an external file", func() {
+			BeforeEach(func() {
+				workingDir = "./testdata/init-default/compose-yaml-ref"
+			})
+
+			It("writes the referenced values", func() {
+				svc, err := env.GetService("db")
+				Expect(err).NotTo(HaveOccurred())
+
+				svcK8sConfig, err := config.ParseSvcK8sConfigFromMap(svc.Extensions, config.SkipValidation())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(svcK8sConfig.Workload.Replicas).To(Equal(4))
+				Expect(svcK8sConfig.Workload.LivenessProbe.Type).To(Equal(config.ProbeTypeNone.String()))
+			})
+		})
 	})
 })
@@ -27,6 +27,7 @@ import (
 	kmd "github.com/appvia/komando"
 	"github.com/appvia/tako/pkg/tako/config"
 	"github.com/appvia/tako/pkg/tako/converter"
+	"github.com/appvia/tako/pkg/tako/converter/kubernetes"
 	"github.com/appvia/tako/pkg/tako/log"
 	composego "github.com/compose-spec/compose-go/types"
 	"github.com/google/uuid"
@@ -297,6 +298,14 @@ func (m *Manifest) RenderWithConvertor(c converter.Converter, runc *runConfig) (
 		return nil, err
 	}
 
+	imageTags := ImageTags{}
+	if runc.ImageTagsFile != "" {
+		if imageTags, err = LoadImageTags(runc.ImageTagsFile); err != nil {
+			renderStepError(m.UI, errSg.Add(""), renderStepRenderGeneral, err)
+			return nil, err
+		}
+	}
+
 	rendered := map[string][]byte{}
 	projects := map[string]*composego.Project{}
 	files := map[string][]string{}
@@ -309,6 +318,8 @@ func (m *Manifest) RenderWithConvertor(c converter.Converter, runc *runConfig) (
 			renderStepError(m.UI, errSg.Add(""), renderStepRenderOverlay, wrappedErr)
 			return nil, wrappedErr
 		}
+		imageTags.Apply(env.Name, p.Project)
+
 		projects[env.Name] = p.Project
 		files[env.Name] = append(sourcesFiles, env.File)
 	}
@@ -336,7 +347,7 @@ func (m *Manifest) RenderWithConvertor(c converter.Converter, runc *runConfig) (
 			return nil, err
 		}
 
-		if err = UpdateSkaffoldBuildArtifacts(m.Skaffold, composeProject); err != nil {
+		if err = UpdateSkaffoldBuildArtifacts(m.Skaffold, composeProject, m.skaffoldImageOptions()...); err != nil {
 			decoratedErr := errors.Errorf("Couldn't update skaffold.yaml build artifacts, details:\n%s", err)
 			renderStepError(m.UI, errSg.Add(""), renderStepRenderGeneral, decoratedErr)
 			return nil, err
@@ -346,6 +357,26 @@ func (m *Manifest) RenderWithConvertor(c converter.Converter, runc *runConfig) (
 	return outputPaths, nil
 }
 
+// skaffoldImageOptions returns options naming Skaffold build artifacts consistently with images in rendered K8s manifests.
+func (m *Manifest) skaffoldImageOptions() []SkaffoldManifestOption {
+	var opts []SkaffoldManifestOption
+	if m.ImageRegistry != "" {
+		opts = append(opts, WithImageRegistry(m.ImageRegistry))
+	}
+	if transform := m.imageNameTransformer(); transform != nil {
+		opts = append(opts, WithImageNameTransformer(transform))
+	}
+	return opts
+}
+
+// imageNameTransformer returns the transformer applied to image names, nil when image names are left untouched.
+func (m *Manifest) imageNameTransformer() kubernetes.ImageNameTransformer {
+	if m.NormalizeImageNames {
+		return kubernetes.NormalizeImageName
+	}
+	return nil
+}
+
 // GetSourcesFiles gets the sources tracked docker-compose files.
 func (m *Manifest) GetSourcesFiles() []string {
 	return m.Sources.Files
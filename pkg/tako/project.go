@@ -226,6 +226,55 @@ func WithSkaffold(c bool) Options {
 	}
 }
 
+// WithSkaffoldKubeContexts configures a project's run config to bind Skaffold environment profiles
+// to their `<env>-context` kube-contexts.
+func WithSkaffoldKubeContexts(c bool) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.SkaffoldKubeContexts = c
+	}
+}
+
+// WithSkaffoldManifestsFormat configures a project's run config with a format of K8s manifests
+// deployed by Skaffold environment profiles, i.e. "kubernetes", "kustomize" or "helm".
+func WithSkaffoldManifestsFormat(c string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.SkaffoldManifestsFormat = c
+	}
+}
+
+// WithSkaffoldTagPolicy configures a project's run config with a policy used by Skaffold to tag built images,
+// i.e. "gitTagger", "sha256", "dateTime" or "envTemplate".
+func WithSkaffoldTagPolicy(c string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.SkaffoldTagPolicy = c
+	}
+}
+
+// WithImageRegistryPrefix configures a project's run config with a registry prepended to built images
+// that don't reference a registry, both in Skaffold build artifacts and in rendered K8s manifests.
+func WithImageRegistryPrefix(c string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.ImageRegistry = c
+	}
+}
+
+// WithImageNameNormalization configures a project's run config to normalize image names with kubernetes.NormalizeImageName,
+// both in Skaffold build artifacts and in rendered K8s manifests.
+func WithImageNameNormalization(c bool) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.NormalizeImageNames = c
+	}
+}
+
+// WithSkaffoldStatusCheck configures a project's run config with whether Skaffold environment profiles
+// wait for deployed resources to stabilize, and for how long. Zero deadline uses the default one.
+func WithSkaffoldStatusCheck(enabled bool, deadlineSeconds int) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.SkaffoldStatusCheckDisabled = !enabled
+		cfg.SkaffoldStatusCheckDeadlineSeconds = deadlineSeconds
+	}
+}
+
 // WithManifestFormat configures a project's run config with a K8s manifest format for rendering.
 func WithManifestFormat(c string) Options {
 	return func(project *Project, cfg *runConfig) {
@@ -249,6 +298,22 @@ func WithAdditionalManifests(c []string) Options {
 	}
 }
 
+// WithLegacySecretKeys configures a project's run config to store and mount single file secrets
+// under the secret name instead of the secret file base name.
+func WithLegacySecretKeys(c bool) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.LegacySecretKeys = c
+	}
+}
+
+// WithImageTagsFile configures a project's run config with a file of per environment service image tags,
+// so the same service can be rendered with a different image tag for each environment.
+func WithImageTagsFile(c string) Options {
+	return func(project *Project, cfg *runConfig) {
+		cfg.ImageTagsFile = c
+	}
+}
+
 // WithOutputDir configures a project's run config with a location to render a project's K8s manifests.
 func WithOutputDir(c string) Options {
 	return func(project *Project, cfg *runConfig) {
@@ -23,6 +23,7 @@ import (
 	kmd "github.com/appvia/komando"
 	"github.com/appvia/tako/pkg/tako/config"
 	"github.com/appvia/tako/pkg/tako/converter"
+	"github.com/appvia/tako/pkg/tako/converter/kubernetes"
 	"github.com/pkg/errors"
 )
 
@@ -212,7 +213,28 @@ func (r *RenderRunner) RenderFromComposeToK8sManifests() (map[string]string, err
 	manifestFormat := r.config.ManifestFormat
 	r.UI.Header(fmt.Sprintf("Rendering manifests, format: %s...", manifestFormat))
 
-	results, err := r.manifest.RenderWithConvertor(converter.Factory(manifestFormat, r.UI), r.config)
+	opt := kubernetes.ConvertOptions{
+		LegacySecretKeys:     r.config.LegacySecretKeys,
+		ImageRegistryPrefix:  r.manifest.ImageRegistry,
+		ImageNameTransformer: r.manifest.imageNameTransformer(),
+	}
+
+	// render manifests in the format deployed by Skaffold environment profiles
+	if len(r.manifest.Skaffold) > 0 {
+		skManifest, err := LoadSkaffoldManifest(r.manifest.Skaffold)
+		if err != nil {
+			sg := r.UI.StepGroup()
+			defer sg.Done()
+			renderStepError(r.UI, sg.Add(""), renderStepLoadSkaffold, err)
+			return nil, err
+		}
+
+		format := skManifest.ManifestsFormat()
+		opt.CreateChart = format == HelmManifestsFormat
+		opt.GenerateKustomization = format == KustomizeManifestsFormat
+	}
+
+	results, err := r.manifest.RenderWithConvertor(converter.FactoryWithOptions(manifestFormat, r.UI, opt), r.config)
 	if err != nil {
 		return nil, err
 	}
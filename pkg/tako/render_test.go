@@ -0,0 +1,182 @@
+/**
+ * Copyright 2021 Appvia Ltd <info@appvia.io>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tako_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/appvia/tako/pkg/tako"
+	"github.com/appvia/tako/pkg/tako/converter/kubernetes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Render", func() {
+	var (
+		composePath = "init-default/compose-yml/compose.yml"
+		wd          string
+		cwd         string
+		err         error
+	)
+
+	BeforeEach(func() {
+		wd, err = NewTempWorkingDir(composePath)
+		Expect(err).NotTo(HaveOccurred())
+
+		// skaffold manifest is referenced relative to the current directory
+		cwd, err = os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(wd)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.Chdir(cwd)).To(Succeed())
+		Expect(os.RemoveAll(wd)).To(Succeed())
+	})
+
+	Context("for project deploying helm charts with skaffold", func() {
+		BeforeEach(func() {
+			Expect(tako.InitProjectWithOptions(wd,
+				tako.WithEnvs([]string{"dev"}),
+				tako.WithSkaffold(true),
+				tako.WithSkaffoldManifestsFormat(string(tako.HelmManifestsFormat)),
+			)).To(Succeed())
+		})
+
+		It("renders a chart the environment helm release points at", func() {
+			Expect(tako.RenderProjectWithOptions(wd)).To(Succeed())
+
+			skManifest, err := tako.LoadSkaffoldManifest(tako.SkaffoldFileName)
+			Expect(err).NotTo(HaveOccurred())
+
+			var release string
+			var values []string
+			for _, p := range skManifest.Profiles {
+				if p.Name == "dev"+tako.EnvProfileNameSuffix {
+					release = p.Deploy.LegacyHelmDeploy.Releases[0].ChartPath
+					values = p.Deploy.LegacyHelmDeploy.Releases[0].ValuesFiles
+				}
+			}
+			Expect(release).NotTo(BeEmpty())
+
+			Expect(filepath.Join(release, "Chart.yaml")).To(BeARegularFile())
+			Expect(filepath.Join(release, "templates", "db-statefulset.yaml")).To(BeARegularFile())
+			for _, v := range values {
+				Expect(v).To(BeARegularFile())
+			}
+		})
+	})
+
+	Context("for project deploying kustomizations with skaffold", func() {
+		BeforeEach(func() {
+			Expect(tako.InitProjectWithOptions(wd,
+				tako.WithEnvs([]string{"dev"}),
+				tako.WithSkaffold(true),
+				tako.WithSkaffoldManifestsFormat(string(tako.KustomizeManifestsFormat)),
+			)).To(Succeed())
+		})
+
+		It("renders a kustomization the environment profile points at", func() {
+			Expect(tako.RenderProjectWithOptions(wd)).To(Succeed())
+
+			// rendering again must not lose the kustomization
+			Expect(tako.RenderProjectWithOptions(wd)).To(Succeed())
+
+			skManifest, err := tako.LoadSkaffoldManifest(tako.SkaffoldFileName)
+			Expect(err).NotTo(HaveOccurred())
+
+			var paths []string
+			for _, p := range skManifest.Profiles {
+				if p.Name == "dev"+tako.EnvProfileNameSuffix {
+					paths = p.Render.Generate.Kustomize.Paths
+				}
+			}
+			Expect(paths).To(HaveLen(1))
+
+			data, err := os.ReadFile(filepath.Join(paths[0], kubernetes.KustomizationFileName))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("- db-statefulset.yaml"))
+		})
+	})
+
+	Context("for project building images pushed to a registry", func() {
+		BeforeEach(func() {
+			data, err := os.ReadFile(filepath.Join(cwd, "testdata", "init-default", "compose-yml-build", "compose.yml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(wd, "compose.yml"), data, 0600)).To(Succeed())
+
+			Expect(tako.InitProjectWithOptions(wd,
+				tako.WithEnvs([]string{"dev"}),
+				tako.WithSkaffold(true),
+				tako.WithImageRegistryPrefix("quay.io"),
+			)).To(Succeed())
+		})
+
+		artifactImages := func() []string {
+			skManifest, err := tako.LoadSkaffoldManifest(tako.SkaffoldFileName)
+			Expect(err).NotTo(HaveOccurred())
+
+			images := []string{}
+			for _, a := range skManifest.Build.Artifacts {
+				images = append(images, a.ImageName)
+			}
+			return images
+		}
+
+		It("keeps artifact images prefixed with the registry after render", func() {
+			Expect(artifactImages()).To(ConsistOf("quay.io/myorg/web"))
+
+			Expect(tako.RenderProjectWithOptions(wd)).To(Succeed())
+			Expect(artifactImages()).To(ConsistOf("quay.io/myorg/web"))
+		})
+
+		It("prefixes images in rendered K8s manifests with the registry", func() {
+			Expect(tako.RenderProjectWithOptions(wd)).To(Succeed())
+
+			data, err := os.ReadFile(filepath.Join("k8s", "dev", "web-deployment.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("image: quay.io/myorg/web"))
+		})
+	})
+
+	Context("for project building images with names invalid in image references", func() {
+		BeforeEach(func() {
+			compose := []byte("services:\n  web:\n    image: MyOrg/Web_App\n    build:\n      context: ./web\n")
+			Expect(os.WriteFile(filepath.Join(wd, "compose.yml"), compose, 0600)).To(Succeed())
+
+			Expect(tako.InitProjectWithOptions(wd,
+				tako.WithEnvs([]string{"dev"}),
+				tako.WithSkaffold(true),
+				tako.WithImageNameNormalization(true),
+			)).To(Succeed())
+		})
+
+		It("normalizes image names in skaffold build artifacts and rendered K8s manifests", func() {
+			Expect(tako.RenderProjectWithOptions(wd)).To(Succeed())
+
+			skManifest, err := tako.LoadSkaffoldManifest(tako.SkaffoldFileName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(skManifest.Build.Artifacts).To(HaveLen(1))
+			Expect(skManifest.Build.Artifacts[0].ImageName).To(Equal("myorg/web_app"))
+
+			data, err := os.ReadFile(filepath.Join("k8s", "dev", "web-deployment.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("image: myorg/web_app"))
+		})
+	})
+})
@@ -74,6 +74,207 @@ const (
 
 	// DefaultSkaffoldNamespace is a default namespace to which Skaffold will deploy manifests
 	DefaultSkaffoldNamespace = "default"
+
+	// DefaultBuildpacksBuilderImage is a default builder image used by buildpacks artifacts
+	DefaultBuildpacksBuilderImage = "paketobuildpacks/builder:base"
+
+	// DefaultStatusCheckDeadlineSeconds is a default time environment profiles wait for deployed resources to stabilize
+	DefaultStatusCheckDeadlineSeconds = 600
+
+	// SkaffoldSyncExtensionKey is a compose service extension holding file sync rules of the service artifact
+	SkaffoldSyncExtensionKey = "x-skaffold-sync"
+)
+
+type skaffoldManifestOptions struct {
+	builderImage     string
+	kaniko           bool
+	clusterNamespace string
+	tagPolicy        TagPolicy
+	imageRegistry    string
+	imageName        kubernetes.ImageNameTransformer
+	statusCheck      *bool
+	statusCheckSecs  int
+	kubeContexts     bool
+	syncRules        map[string]*latest.Sync
+	portForwards     []*latest.PortForwardResource
+	manifestsFormat  ManifestsFormat
+}
+
+// SkaffoldManifestOption will modify generation of the skaffold manifest.
+type SkaffoldManifestOption func(*skaffoldManifestOptions)
+
+// WithBuilderImage sets the builder image used by buildpacks artifacts, e.g. a pinned digest or a different provider.
+func WithBuilderImage(image string) SkaffoldManifestOption {
+	return func(opts *skaffoldManifestOptions) {
+		opts.builderImage = image
+	}
+}
+
+// WithKanikoClusterBuild builds artifacts in-cluster with Kaniko instead of the local docker daemon,
+// e.g. for CI environments without docker. Kaniko pods are scheduled in the given namespace.
+func WithKanikoClusterBuild(namespace string) SkaffoldManifestOption {
+	return func(opts *skaffoldManifestOptions) {
+		opts.kaniko = true
+		opts.clusterNamespace = namespace
+	}
+}
+
+// WithImageRegistry prefixes artifact image names that don't reference a registry,
+// consistently with images rewritten in the rendered K8s manifests.
+func WithImageRegistry(registry string) SkaffoldManifestOption {
+	return func(opts *skaffoldManifestOptions) {
+		opts.imageRegistry = registry
+	}
+}
+
+// WithImageNameTransformer maps artifact image names, e.g. to comply with registry naming rules.
+// It must match the image name transformer used when rendering K8s manifests. Image names are left untouched by default.
+func WithImageNameTransformer(transform kubernetes.ImageNameTransformer) SkaffoldManifestOption {
+	return func(opts *skaffoldManifestOptions) {
+		opts.imageName = transform
+	}
+}
+
+// WithStatusCheck configures whether environment profiles wait for deployed resources to stabilize,
+// and for how long. Status check is enabled with DefaultStatusCheckDeadlineSeconds deadline by default.
+func WithStatusCheck(enabled bool, deadlineSeconds int) SkaffoldManifestOption {
+	return func(opts *skaffoldManifestOptions) {
+		opts.statusCheck = &enabled
+		opts.statusCheckSecs = deadlineSeconds
+	}
+}
+
+// WithKubeContextActivation binds each environment profile to the `<env>-context` kube-context,
+// so the profile auto-activates and deploys only when that kube-context is in use.
+func WithKubeContextActivation() SkaffoldManifestOption {
+	return func(opts *skaffoldManifestOptions) {
+		opts.kubeContexts = true
+	}
+}
+
+// WithManifestsFormat sets the format of rendered K8s manifests deployed by environment profiles.
+// Raw K8s manifests are deployed by default.
+func WithManifestsFormat(format ManifestsFormat) SkaffoldManifestOption {
+	return func(opts *skaffoldManifestOptions) {
+		opts.manifestsFormat = format
+	}
+}
+
+// WithPortForwards forwards ports published by project services in each environment profile.
+func WithPortForwards(project *ComposeProject) SkaffoldManifestOption {
+	return func(opts *skaffoldManifestOptions) {
+		opts.portForwards = portForwards(project)
+	}
+}
+
+// WithSyncRules sets file sync rules, keyed by compose service name, on artifacts built from service image.
+// Sync rules let `skaffold dev` copy changed files into running containers instead of rebuilding images.
+// They take precedence over rules configured via the `x-skaffold-sync` service extension.
+// Rules with invalid glob patterns are skipped, use ValidateSyncRules to check them upfront.
+func WithSyncRules(rules map[string]*latest.Sync) SkaffoldManifestOption {
+	return func(opts *skaffoldManifestOptions) {
+		opts.syncRules = rules
+	}
+}
+
+// ValidateSyncRules returns an error when any sync rule glob pattern is malformed
+func ValidateSyncRules(rules map[string]*latest.Sync) error {
+	for svc, sync := range rules {
+		if sync == nil {
+			continue
+		}
+
+		patterns := append([]string{}, sync.Infer...)
+		for _, r := range sync.Manual {
+			if r == nil || r.Src == "" || r.Dest == "" {
+				return fmt.Errorf("service %s manual sync rule requires both src and dest", svc)
+			}
+			patterns = append(patterns, r.Src)
+		}
+
+		for _, p := range patterns {
+			if _, err := filepath.Match(p, ""); err != nil {
+				return fmt.Errorf("service %s sync rule pattern %q is invalid: %w", svc, p, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// WithTagPolicy sets the policy used to tag built images. Defaults to git tagger when not specified.
+func WithTagPolicy(policy TagPolicy) SkaffoldManifestOption {
+	return func(opts *skaffoldManifestOptions) {
+		opts.tagPolicy = policy
+	}
+}
+
+// TagPolicy is a Skaffold policy used to tag built images
+type TagPolicy string
+
+const (
+	// GitTagPolicy tags images with git tags of the current commit
+	GitTagPolicy TagPolicy = "gitTagger"
+
+	// Sha256TagPolicy tags images with their digest
+	Sha256TagPolicy TagPolicy = "sha256"
+
+	// DateTimeTagPolicy tags images with the build timestamp
+	DateTimeTagPolicy TagPolicy = "dateTime"
+
+	// EnvTemplateTagPolicy tags images with a tag templated from environment variables
+	EnvTemplateTagPolicy TagPolicy = "envTemplate"
+
+	// DefaultEnvTemplate is a template used by envTemplate tag policy
+	DefaultEnvTemplate = "{{.IMAGE_TAG}}"
+)
+
+// ParseTagPolicy returns a supported tag policy or an error. Empty policy defaults to git tagger.
+func ParseTagPolicy(policy string) (TagPolicy, error) {
+	switch p := TagPolicy(policy); p {
+	case "":
+		return GitTagPolicy, nil
+	case GitTagPolicy, Sha256TagPolicy, DateTimeTagPolicy, EnvTemplateTagPolicy:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported tag policy %q, must be one of: %s, %s, %s, %s",
+			policy, GitTagPolicy, Sha256TagPolicy, DateTimeTagPolicy, EnvTemplateTagPolicy)
+	}
+}
+
+// skaffoldTagPolicy returns Skaffold tag policy configuration for the policy
+func skaffoldTagPolicy(policy TagPolicy) latest.TagPolicy {
+	switch policy {
+	case Sha256TagPolicy:
+		return latest.TagPolicy{ShaTagger: &latest.ShaTagger{}}
+	case DateTimeTagPolicy:
+		return latest.TagPolicy{DateTimeTagger: &latest.DateTimeTagger{}}
+	case EnvTemplateTagPolicy:
+		return latest.TagPolicy{EnvTemplateTagger: &latest.EnvTemplateTagger{Template: DefaultEnvTemplate}}
+	default:
+		return latest.TagPolicy{
+			GitTagger: &latest.GitTagger{
+				Variant: "Tags",
+			},
+		}
+	}
+}
+
+// ManifestsFormat is a format of rendered K8s manifests deployed by Skaffold profiles
+type ManifestsFormat string
+
+const (
+	// KubernetesManifestsFormat represents raw kubernetes manifests
+	KubernetesManifestsFormat ManifestsFormat = "kubernetes"
+
+	// KustomizeManifestsFormat represents manifests rendered with kustomize from per environment kustomization
+	KustomizeManifestsFormat ManifestsFormat = "kustomize"
+
+	// HelmManifestsFormat represents per environment Helm chart
+	HelmManifestsFormat ManifestsFormat = "helm"
+
+	// HelmValuesFileName is a name of the Helm chart values file
+	HelmValuesFileName = "values.yaml"
 )
 
 var (
@@ -81,39 +282,86 @@ var (
 	enabled  = true
 )
 
+// ParseManifestsFormat returns the manifests format of the given name, or an error for unsupported formats
+func ParseManifestsFormat(name string) (ManifestsFormat, error) {
+	switch format := ManifestsFormat(name); format {
+	case KubernetesManifestsFormat, KustomizeManifestsFormat, HelmManifestsFormat:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported manifests format %q, use one of: %s, %s, %s",
+			name, KubernetesManifestsFormat, KustomizeManifestsFormat, HelmManifestsFormat)
+	}
+}
+
 // NewSkaffoldManifest returns a new SkaffoldManifest struct.
-func NewSkaffoldManifest(envs []string, project *ComposeProject) *SkaffoldManifest {
+func NewSkaffoldManifest(envs []string, project *ComposeProject, opts ...SkaffoldManifestOption) *SkaffoldManifest {
 
 	// it's OK to pass nil analysis so no error handling necessary here
 	analysis, _ := analyzeProject()
 
-	manifest := BaseSkaffoldManifest()
-	manifest.SetBuildArtifacts(analysis, project)
-	manifest.SetProfiles(envs)
+	manifest := BaseSkaffoldManifest(opts...)
+	manifest.SetBuildArtifacts(analysis, project, opts...)
+	manifest.SetProfiles(envs, append([]SkaffoldManifestOption{WithPortForwards(project)}, opts...)...)
 	manifest.SetAdditionalProfiles()
 
 	return manifest
 }
 
 // LoadSkaffoldManifest returns skaffold manifest.
+// Manifests using an older schema version are upgraded to the latest schema.
 func LoadSkaffoldManifest(path string) (*SkaffoldManifest, error) {
-	data, err := os.ReadFile(path)
+	s, _, err := loadSkaffoldManifest(path)
+	return s, err
+}
+
+// loadSkaffoldManifest returns skaffold manifest upgraded to the latest schema,
+// and whether the manifest used an older schema version.
+func loadSkaffoldManifest(path string) (*SkaffoldManifest, bool, error) {
+	configs, err := schema.ParseConfig(path)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+
+	if len(configs) == 0 {
+		return nil, false, fmt.Errorf("skaffold config file (%s) doesn't contain any config", path)
+	}
+
+	upgraded := configs[0].GetVersion() != latest.Version
+
+	configs, err = schema.UpgradeTo(configs, latest.Version)
+	if err != nil {
+		return nil, false, err
 	}
-	var s *SkaffoldManifest
-	return s, yaml.Unmarshal(data, &s)
+
+	// only the first config is managed, multi config skaffold files aren't supported
+	cfg, ok := configs[0].(*latest.SkaffoldConfig)
+	if !ok {
+		return nil, false, fmt.Errorf("skaffold config file (%s) can't be upgraded to %s", path, latest.Version)
+	}
+
+	s := SkaffoldManifest(*cfg)
+	return &s, upgraded, nil
 }
 
 // InjectProfiles injects Tako profiles to existing Skaffold manifest
-// Note, if profile name already exists in the skaffold manifest then profile won't be added
-func InjectProfiles(path string, envs []string, includeAdditional bool) (*SkaffoldManifest, error) {
+// Note, if profile name already exists in the skaffold manifest then profile won't be added.
+// The tag policy set with WithTagPolicy replaces the existing build tag policy.
+func InjectProfiles(path string, envs []string, includeAdditional bool, opts ...SkaffoldManifestOption) (*SkaffoldManifest, error) {
 	skaffold, err := LoadSkaffoldManifest(path)
 	if err != nil {
 		return nil, err
 	}
 
-	skaffold.SetProfiles(envs)
+	var options skaffoldManifestOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if options.tagPolicy != "" {
+		skaffold.Build.TagPolicy = skaffoldTagPolicy(options.tagPolicy)
+	}
+
+	skaffold.SetProfiles(envs, opts...)
 	if includeAdditional {
 		skaffold.SetAdditionalProfiles()
 	}
@@ -124,12 +372,12 @@ func InjectProfiles(path string, envs []string, includeAdditional bool) (*Skaffo
 // UpdateSkaffoldBuildArtifacts updates skaffold build artefacts with freshly discovered list of images and contexts.
 // Note, it'll persist updated build artefacts in the skaffold.yaml file only when change in build artefacts was detected.
 // Important: The last discovered images and contexts will be persisted (if changed)!
-func UpdateSkaffoldBuildArtifacts(path string, project *ComposeProject) error {
+func UpdateSkaffoldBuildArtifacts(path string, project *ComposeProject, opts ...SkaffoldManifestOption) error {
 	if !fileExists(path) {
 		return fmt.Errorf("skaffold config file (%s) doesn't exist", path)
 	}
 
-	skaffold, err := LoadSkaffoldManifest(path)
+	skaffold, upgraded, err := loadSkaffoldManifest(path)
 	if err != nil {
 		return err
 	}
@@ -137,10 +385,10 @@ func UpdateSkaffoldBuildArtifacts(path string, project *ComposeProject) error {
 	// ignore analysis errors as it's OK to pass nil analysis
 	analysis, _ := analyzeProject()
 
-	changed := skaffold.UpdateBuildArtifacts(analysis, project)
+	changed := skaffold.UpdateBuildArtifacts(analysis, project, opts...)
 
-	// only persist when the list of artifacts changed
-	if changed {
+	// only persist when the list of artifacts changed or schema got upgraded
+	if changed || upgraded {
 		file, err := os.Create(path)
 		if err != nil {
 			return err
@@ -155,8 +403,9 @@ func UpdateSkaffoldBuildArtifacts(path string, project *ComposeProject) error {
 }
 
 // UpdateBuildArtifacts sets build artefacts in Skaffold manifest and returns change status
-// true - when list of artefacts was updated, false - otherwise
-func (s *SkaffoldManifest) UpdateBuildArtifacts(analysis *Analysis, project *ComposeProject) bool {
+// true - when list of artefacts was updated, false - otherwise.
+// Options must name artifacts the same way as when the manifest was created, e.g. WithImageRegistry.
+func (s *SkaffoldManifest) UpdateBuildArtifacts(analysis *Analysis, project *ComposeProject, opts ...SkaffoldManifestOption) bool {
 	prevArts := s.Build.Artifacts
 	if prevArts == nil {
 		prevArts = []*latest.Artifact{}
@@ -165,7 +414,7 @@ func (s *SkaffoldManifest) UpdateBuildArtifacts(analysis *Analysis, project *Com
 		return prevArts[i].ImageName < prevArts[j].ImageName
 	})
 
-	s.SetBuildArtifacts(analysis, project)
+	s.SetBuildArtifacts(analysis, project, opts...)
 
 	currArts := s.Build.Artifacts
 	if currArts == nil {
@@ -186,12 +435,12 @@ func UpdateSkaffoldProfiles(path string, envToOutputPath map[string]string) erro
 		return fmt.Errorf("skaffold config file (%s) doesn't exist", path)
 	}
 
-	skaffold, err := LoadSkaffoldManifest(path)
+	skaffold, upgraded, err := loadSkaffoldManifest(path)
 	if err != nil {
 		return err
 	}
 
-	if changed := skaffold.UpdateProfiles(envToOutputPath); changed {
+	if changed := skaffold.UpdateProfiles(envToOutputPath); changed || upgraded {
 		file, err := os.Create(path)
 		if err != nil {
 			return err
@@ -206,31 +455,72 @@ func UpdateSkaffoldProfiles(path string, envToOutputPath map[string]string) erro
 }
 
 // UpdateProfiles updates profile for each environment with its K8s output path
-// Note, currently the only supported format is native kubernetes manifests
+// Note, kustomize profiles get their kustomization path updated, all other profiles get raw manifests path updated
 func (s *SkaffoldManifest) UpdateProfiles(envToOutputPath map[string]string) bool {
 	changed := false
 
-	for _, p := range s.Profiles {
+	for i, p := range s.Profiles {
 
 		// envToOutputPath is keyed by canonical environment name, however
 		// profile names in skaffold manifest might have additional suffix!
 		// We must strip the profile suffix to check the path for that environment.
 		envNameFromProfileName := strings.ReplaceAll(p.Name, EnvProfileNameSuffix, "")
 
-		if outputPath, found := envToOutputPath[envNameFromProfileName]; found {
-			manifestsPath := ""
-			if info, err := os.Stat(outputPath); err == nil && info.IsDir() {
-				manifestsPath = filepath.Join(outputPath, "*")
-			} else if err == nil && info.Mode().IsRegular() {
-				manifestsPath = outputPath
+		outputPath, found := envToOutputPath[envNameFromProfileName]
+		if !found {
+			continue
+		}
+
+		// helm profiles point at the environment chart directory and its values file
+		if helm := p.Deploy.LegacyHelmDeploy; helm != nil && len(helm.Releases) > 0 {
+			release := &helm.Releases[0]
+			valuesFiles := []string{filepath.Join(outputPath, HelmValuesFileName)}
+
+			// only update helm release when necessary
+			if release.ChartPath != outputPath || !reflect.DeepEqual(release.ValuesFiles, valuesFiles) {
+				release.ChartPath = outputPath
+				release.ValuesFiles = valuesFiles
+				changed = true
+			}
+			continue
+		}
+
+		// kustomize profiles point at the environment kustomization directory
+		if kustomize := p.Render.Generate.Kustomize; kustomize != nil {
+			kustomizationPath := outputPath
+			if info, err := os.Stat(outputPath); err == nil && info.Mode().IsRegular() {
+				kustomizationPath = filepath.Dir(outputPath)
 			}
 
-			// only update profile patches when necessary
-			if !reflect.DeepEqual(p.Patches[0].Value.Node.Value(), manifestsPath) {
-				var path interface{} = manifestsPath
-				p.Patches[0].Value.Node = *yamlpatch.NewNode(&path)
+			// only update kustomization paths when necessary
+			if !reflect.DeepEqual(kustomize.Paths, []string{kustomizationPath}) {
+				kustomize.Paths = []string{kustomizationPath}
 				changed = true
 			}
+			continue
+		}
+
+		manifestsPath := ""
+		if info, err := os.Stat(outputPath); err == nil && info.IsDir() {
+			manifestsPath = filepath.Join(outputPath, "*")
+		} else if err == nil && info.Mode().IsRegular() {
+			manifestsPath = outputPath
+		}
+
+		// profiles upgraded from older schema versions reference raw manifests directly instead of patching them
+		if len(p.Patches) == 0 {
+			if !reflect.DeepEqual(p.Render.RawK8s, []string{manifestsPath}) {
+				s.Profiles[i].Render.RawK8s = []string{manifestsPath}
+				changed = true
+			}
+			continue
+		}
+
+		// only update profile patches when necessary
+		if !reflect.DeepEqual(p.Patches[0].Value.Node.Value(), manifestsPath) {
+			var path interface{} = manifestsPath
+			p.Patches[0].Value.Node = *yamlpatch.NewNode(&path)
+			changed = true
 		}
 	}
 
@@ -238,7 +528,12 @@ func (s *SkaffoldManifest) UpdateProfiles(envToOutputPath map[string]string) boo
 }
 
 // BaseSkaffoldManifest returns base Skaffold manifest
-func BaseSkaffoldManifest() *SkaffoldManifest {
+func BaseSkaffoldManifest(opts ...SkaffoldManifestOption) *SkaffoldManifest {
+	var options skaffoldManifestOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
 	return &SkaffoldManifest{
 		APIVersion: latest.Version,
 		Kind:       "Config",
@@ -254,11 +549,7 @@ func BaseSkaffoldManifest() *SkaffoldManifest {
 					// the current Kubernetes context connects to a remote cluster.
 					LocalBuild: &latest.LocalBuild{},
 				},
-				TagPolicy: latest.TagPolicy{
-					GitTagger: &latest.GitTagger{
-						Variant: "Tags",
-					},
-				},
+				TagPolicy: skaffoldTagPolicy(options.tagPolicy),
 			},
 			// @todo(mc) by default the list of raw k8s manifests is empty as it'll be overridden by profiles
 			Render: latest.RenderConfig{
@@ -270,9 +561,32 @@ func BaseSkaffoldManifest() *SkaffoldManifest {
 	}
 }
 
-// SetProfiles adds Skaffold profiles for all Tako project environments
-// when list of environments is empty it will add profile for defaultEnvs
-func (s *SkaffoldManifest) SetProfiles(envs []string) {
+// SetProfiles adds Skaffold profiles for all Tako project environments deploying manifests
+// in the format set with WithManifestsFormat, raw K8s manifests by default.
+// When list of environments is empty it will add profile for defaultEnvs
+func (s *SkaffoldManifest) SetProfiles(envs []string, opts ...SkaffoldManifestOption) {
+	options := skaffoldManifestOptions{
+		manifestsFormat: KubernetesManifestsFormat,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	s.SetProfilesForFormat(envs, options.manifestsFormat, opts...)
+}
+
+// SetProfilesForFormat adds Skaffold profiles for all Tako project environments deploying
+// manifests in the given format. When list of environments is empty it will add profile for defaultEnvs
+func (s *SkaffoldManifest) SetProfilesForFormat(envs []string, format ManifestsFormat, opts ...SkaffoldManifestOption) {
+	statusCheck := true
+	options := skaffoldManifestOptions{
+		statusCheck:     &statusCheck,
+		statusCheckSecs: DefaultStatusCheckDeadlineSeconds,
+		portForwards:    []*latest.PortForwardResource{},
+	}
+	for _, o := range opts {
+		o(&options)
+	}
 
 	if len(envs) == 0 {
 		envs = []string{SandboxEnv}
@@ -284,32 +598,139 @@ func (s *SkaffoldManifest) SetProfiles(envs []string) {
 			continue
 		}
 
-		var envManifestsPath interface{} = filepath.Join(kubernetes.MultiFileSubDir, e, "*")
-
-		patch := latest.JSONPatch{
-			Op:   "replace",
-			Path: "/manifests/rawYaml/0",
-		}
-		patch.Value = &util.YamlpatchNode{Node: *yamlpatch.NewNode(&envManifestsPath)}
-
-		s.Profiles = append(s.Profiles, latest.Profile{
+		profile := latest.Profile{
 			Name: e + EnvProfileNameSuffix,
 			Pipeline: latest.Pipeline{
 				Deploy: latest.DeployConfig{
 					DeployType: latest.DeployType{
-						// @todo(mc) strategy will depend on the output format so deploy
-						// type might mutate as well when iterating with Tako
+						// kustomize manifests are rendered by skaffold and applied with kubectl too
 						KubectlDeploy: &latest.KubectlDeploy{},
 					},
 				},
 				Test:        []*latest.TestCase{},
-				PortForward: []*latest.PortForwardResource{},
+				PortForward: options.portForwards,
 			},
-			Patches: []latest.JSONPatch{patch},
-		})
+		}
+
+		switch format {
+		case HelmManifestsFormat:
+			// deploy environment chart as a helm release instead of raw manifests
+			chartPath := filepath.Join(kubernetes.MultiFileSubDir, e)
+			profile.Deploy = latest.DeployConfig{
+				DeployType: latest.DeployType{
+					LegacyHelmDeploy: &latest.LegacyHelmDeploy{
+						Releases: []latest.HelmRelease{
+							{
+								Name:        e,
+								ChartPath:   chartPath,
+								ValuesFiles: []string{filepath.Join(chartPath, HelmValuesFileName)},
+							},
+						},
+					},
+				},
+			}
+			profile.Patches = []latest.JSONPatch{
+				{
+					Op:   "remove",
+					Path: "/manifests/rawYaml",
+				},
+			}
+		case KustomizeManifestsFormat:
+			// render manifests from the kustomization written alongside environment manifests on render
+			profile.Render = latest.RenderConfig{
+				Generate: latest.Generate{
+					Kustomize: &latest.Kustomize{
+						Paths: []string{filepath.Join(kubernetes.MultiFileSubDir, e)},
+					},
+				},
+			}
+			profile.Patches = []latest.JSONPatch{
+				{
+					Op:   "remove",
+					Path: "/manifests/rawYaml",
+				},
+			}
+		default:
+			var envManifestsPath interface{} = filepath.Join(kubernetes.MultiFileSubDir, e, "*")
+
+			patch := latest.JSONPatch{
+				Op:   "replace",
+				Path: "/manifests/rawYaml/0",
+			}
+			patch.Value = &util.YamlpatchNode{Node: *yamlpatch.NewNode(&envManifestsPath)}
+
+			profile.Patches = []latest.JSONPatch{patch}
+		}
+
+		// bind profile to the environment kube-context
+		if options.kubeContexts {
+			kubeContext := e + EnvProfileKubeContextSuffix
+			profile.Activation = []latest.Activation{{KubeContext: kubeContext}}
+			profile.Deploy.KubeContext = kubeContext
+		}
+
+		// wait for deployed resources to stabilize, each profile gets its own flag so they can be changed independently
+		profileStatusCheck := *options.statusCheck
+		profile.Deploy.StatusCheck = &profileStatusCheck
+		if profileStatusCheck {
+			profile.Deploy.StatusCheckDeadlineSeconds = options.statusCheckSecs
+		}
+
+		s.Profiles = append(s.Profiles, profile)
 	}
 }
 
+// ManifestsFormat returns the format of K8s manifests deployed by environment profiles.
+// Raw K8s manifests are assumed when no environment profile deploys a helm chart or a kustomization.
+func (s *SkaffoldManifest) ManifestsFormat() ManifestsFormat {
+	for _, p := range s.Profiles {
+		if !strings.HasSuffix(p.Name, EnvProfileNameSuffix) {
+			continue
+		}
+
+		if p.Deploy.LegacyHelmDeploy != nil {
+			return HelmManifestsFormat
+		}
+
+		if p.Render.Generate.Kustomize != nil {
+			return KustomizeManifestsFormat
+		}
+	}
+
+	return KubernetesManifestsFormat
+}
+
+// portForwards returns port forward resources for ports published by project services.
+// Services without published ports are skipped.
+func portForwards(project *ComposeProject) []*latest.PortForwardResource {
+	resources := []*latest.PortForwardResource{}
+
+	if project == nil || project.Project == nil {
+		return resources
+	}
+
+	for _, svc := range project.Project.Services {
+		seen := map[uint32]bool{}
+
+		for _, p := range svc.Ports {
+			if p.Published == 0 || seen[p.Published] {
+				continue
+			}
+			seen[p.Published] = true
+
+			// K8s service exposes published port, see kubernetes.configServicePorts
+			resources = append(resources, &latest.PortForwardResource{
+				Type:      "service",
+				Name:      kubernetes.ServiceName(svc.Name),
+				Port:      util.FromInt(int(p.Published)),
+				LocalPort: int(p.Published),
+			})
+		}
+	}
+
+	return resources
+}
+
 // SetAdditionalProfiles adds additional Skaffold profiles
 func (s *SkaffoldManifest) SetAdditionalProfiles() {
 
@@ -352,29 +773,82 @@ func (s *SkaffoldManifest) AddProfileIfNotPresent(p latest.Profile) {
 }
 
 // SetBuildArtifacts detects build artifacts from the current project and adds `build` section to the manifest
-func (s *SkaffoldManifest) SetBuildArtifacts(analysis *Analysis, project *ComposeProject) {
+func (s *SkaffoldManifest) SetBuildArtifacts(analysis *Analysis, project *ComposeProject, opts ...SkaffoldManifestOption) {
+	var options skaffoldManifestOptions
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if options.kaniko {
+		// cluster build replaces the default local build strategy
+		s.Build.BuildType = latest.BuildType{
+			Cluster: &latest.ClusterDetails{
+				Namespace: options.clusterNamespace,
+			},
+		}
+	}
+
+	// preserve previously configured cluster build unless explicitly specified
+	kaniko := s.Build.Cluster != nil
+
 	artifacts := []*latest.Artifact{}
 
 	existingArtifacts := s.Build.Artifacts
+	dockerfiles := collectDockerfiles(analysis, project)
+	syncRules := collectSyncRules(project, options.syncRules)
 
 	for context, image := range collectBuildArtifacts(analysis, project) {
+		sourceImage := image
+		if options.imageName != nil {
+			image = options.imageName(image)
+		}
+		image = kubernetes.PrefixImageRegistry(image, options.imageRegistry)
+
 		artifact := &latest.Artifact{
 			ImageName: image,
 			Workspace: context,
 		}
 
+		builder := options.builderImage
+
 		// if skaffold contains sync rules for particular artifact, we need to preserve them
 		for _, a := range existingArtifacts {
 			if a.ImageName == image && a.Workspace == context && a.Sync != nil {
 				artifact.Sync = a.Sync
 			}
+
+			// preserve previously configured builder image unless explicitly specified
+			if a.ImageName == image && a.Workspace == context && a.BuildpackArtifact != nil && builder == "" {
+				builder = a.BuildpackArtifact.Builder
+			}
 		}
 
-		if analysis == nil || analysis.Dockerfiles == nil || len(analysis.Dockerfiles) == 0 {
+		// configured sync rules take precedence over the preserved ones
+		if sync, ok := syncRules[sourceImage]; ok {
+			artifact.Sync = sync
+		}
+
+		if builder == "" {
+			builder = DefaultBuildpacksBuilderImage
+		}
+
+		if kaniko {
+			// kaniko builds from Dockerfile only, default to the conventional name when none was discovered
+			dockerfile, ok := dockerfiles[context]
+			if !ok {
+				dockerfile = "Dockerfile"
+			}
+
+			artifact.ArtifactType = latest.ArtifactType{
+				KanikoArtifact: &latest.KanikoArtifact{
+					DockerfilePath: dockerfile,
+				},
+			}
+		} else if analysis == nil || analysis.Dockerfiles == nil || len(analysis.Dockerfiles) == 0 {
 			// no Dockerfiles detected, set `buildpacks` as build strategy for the artifact
 			artifact.ArtifactType = latest.ArtifactType{
 				BuildpackArtifact: &latest.BuildpackArtifact{
-					Builder: "paketobuildpacks/builder:base",
+					Builder: builder,
 				},
 			}
 		}
@@ -451,6 +925,91 @@ func collectBuildArtifacts(analysis *Analysis, project *ComposeProject) map[stri
 	return buildArtifacts
 }
 
+// collectSyncRules returns a map of compose service images to sync rules of services built from a context.
+// Rules passed explicitly take precedence over rules configured via the service extension.
+func collectSyncRules(project *ComposeProject, rules map[string]*latest.Sync) map[string]*latest.Sync {
+	out := map[string]*latest.Sync{}
+
+	if project == nil || project.Project == nil {
+		return out
+	}
+
+	for _, s := range project.Project.Services {
+		if s.Build == nil || len(s.Build.Context) == 0 || len(s.Image) == 0 {
+			continue
+		}
+
+		sync, ok := rules[s.Name]
+		if !ok {
+			var err error
+			if sync, err = serviceSyncRules(s.Extensions); err != nil {
+				log.WarnWithFields(log.Fields{
+					"service": s.Name,
+				}, err.Error())
+				continue
+			}
+		}
+
+		if sync == nil {
+			continue
+		}
+
+		if err := ValidateSyncRules(map[string]*latest.Sync{s.Name: sync}); err != nil {
+			log.WarnWithFields(log.Fields{
+				"service": s.Name,
+			}, err.Error())
+			continue
+		}
+
+		out[s.Image] = sync
+	}
+
+	return out
+}
+
+// serviceSyncRules returns sync rules configured via the service extension, nil if not configured
+func serviceSyncRules(extensions map[string]interface{}) (*latest.Sync, error) {
+	ext, ok := extensions[SkaffoldSyncExtensionKey]
+	if !ok || ext == nil {
+		return nil, nil
+	}
+
+	data, err := yaml.Marshal(ext)
+	if err != nil {
+		return nil, err
+	}
+
+	sync := &latest.Sync{}
+	if err := yaml.UnmarshalStrict(data, sync); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s extension", SkaffoldSyncExtensionKey)
+	}
+
+	return sync, nil
+}
+
+// collectDockerfiles returns a map of build contexts to Dockerfile paths relative to the context
+func collectDockerfiles(analysis *Analysis, project *ComposeProject) map[string]string {
+	dockerfiles := map[string]string{}
+
+	if analysis != nil {
+		for _, d := range analysis.Dockerfiles {
+			context := filepath.Dir(d)
+			dockerfiles[context] = filepath.Base(d)
+		}
+	}
+
+	// Dockerfile referenced explicitly in Docker Compose takes precedence over the detected one
+	if project != nil && project.Project != nil {
+		for _, s := range project.Project.Services {
+			if s.Build != nil && len(s.Build.Context) > 0 && len(s.Build.Dockerfile) > 0 {
+				dockerfiles[s.Build.Context] = s.Build.Dockerfile
+			}
+		}
+	}
+
+	return dockerfiles
+}
+
 // analyzeProject analyses the project and returns Analysis report object
 func analyzeProject() (*Analysis, error) {
 	c := initconfig.Config{
@@ -18,6 +18,7 @@ package tako_test
 
 import (
 	"bytes"
+	"os"
 	"path/filepath"
 
 	"github.com/GoogleContainerTools/skaffold/v2/pkg/skaffold/schema/latest"
@@ -86,6 +87,34 @@ var _ = Describe("Skaffold", func() {
 				},
 			))
 		})
+
+		It("emits each supported tag policy when configured", func() {
+			expected := map[tako.TagPolicy]latest.TagPolicy{
+				tako.GitTagPolicy:         {GitTagger: &latest.GitTagger{Variant: "Tags"}},
+				tako.Sha256TagPolicy:      {ShaTagger: &latest.ShaTagger{}},
+				tako.DateTimeTagPolicy:    {DateTimeTagger: &latest.DateTimeTagger{}},
+				tako.EnvTemplateTagPolicy: {EnvTemplateTagger: &latest.EnvTemplateTagger{Template: tako.DefaultEnvTemplate}},
+			}
+
+			for policy, tagPolicy := range expected {
+				Expect(tako.BaseSkaffoldManifest(tako.WithTagPolicy(policy)).Build.TagPolicy).To(Equal(tagPolicy))
+			}
+		})
+	})
+
+	Describe("ParseTagPolicy", func() {
+		It("defaults to git tagger when policy isn't specified", func() {
+			Expect(tako.ParseTagPolicy("")).To(Equal(tako.GitTagPolicy))
+		})
+
+		It("accepts supported policies", func() {
+			Expect(tako.ParseTagPolicy("sha256")).To(Equal(tako.Sha256TagPolicy))
+		})
+
+		It("returns an error for unsupported policies", func() {
+			_, err := tako.ParseTagPolicy("inputDigest")
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
 	Describe("SetProfiles", func() {
@@ -103,10 +132,13 @@ var _ = Describe("Skaffold", func() {
 
 			It("generates correct pipeline Deploy section for each environment", func() {
 				for i, p := range manifest.Profiles {
+					statusCheck := true
 					Expect(p.Deploy).To(Equal(latest.DeployConfig{
 						DeployType: latest.DeployType{
 							KubectlDeploy: &latest.KubectlDeploy{},
 						},
+						StatusCheck:                &statusCheck,
+						StatusCheckDeadlineSeconds: tako.DefaultStatusCheckDeadlineSeconds,
 					}))
 
 					var expectedEnvManifestsPath interface{} = filepath.Join(kubernetes.MultiFileSubDir, envs[i], "*")
@@ -139,6 +171,110 @@ var _ = Describe("Skaffold", func() {
 			})
 		})
 
+		When("kube-context activation is enabled", func() {
+
+			envs := []string{"dev", "prod"}
+			manifest := tako.BaseSkaffoldManifest()
+			manifest.SetProfiles(envs, tako.WithKubeContextActivation())
+
+			It("binds each environment profile to its kube-context", func() {
+				for i, p := range manifest.Profiles {
+					kubeContext := envs[i] + tako.EnvProfileKubeContextSuffix
+					Expect(p.Activation).To(Equal([]latest.Activation{{KubeContext: kubeContext}}))
+					Expect(p.Deploy.KubeContext).To(Equal(kubeContext))
+				}
+			})
+		})
+
+		When("kube-context activation isn't enabled", func() {
+
+			manifest := tako.BaseSkaffoldManifest()
+			manifest.SetProfiles([]string{"dev"})
+
+			It("doesn't bind environment profiles to kube-contexts", func() {
+				Expect(manifest.Profiles[0].Activation).To(BeEmpty())
+				Expect(manifest.Profiles[0].Deploy.KubeContext).To(BeEmpty())
+			})
+		})
+
+		When("status check is configured", func() {
+
+			envs := []string{"dev"}
+
+			It("sets configured status check deadline in each environment profile deploy config", func() {
+				manifest := tako.BaseSkaffoldManifest()
+				manifest.SetProfiles(envs, tako.WithStatusCheck(true, 120))
+
+				Expect(*manifest.Profiles[0].Deploy.StatusCheck).To(BeTrue())
+				Expect(manifest.Profiles[0].Deploy.StatusCheckDeadlineSeconds).To(Equal(120))
+			})
+
+			It("disables status check without a deadline when requested", func() {
+				manifest := tako.BaseSkaffoldManifest()
+				manifest.SetProfiles(envs, tako.WithStatusCheck(false, 120))
+
+				Expect(*manifest.Profiles[0].Deploy.StatusCheck).To(BeFalse())
+				Expect(manifest.Profiles[0].Deploy.StatusCheckDeadlineSeconds).To(BeZero())
+			})
+
+			It("lets each environment profile status check change independently", func() {
+				manifest := tako.BaseSkaffoldManifest()
+				manifest.SetProfiles([]string{"dev", "prod"})
+
+				*manifest.Profiles[0].Deploy.StatusCheck = false
+
+				Expect(*manifest.Profiles[1].Deploy.StatusCheck).To(BeTrue())
+
+				other := tako.BaseSkaffoldManifest()
+				other.SetProfiles([]string{"dev"})
+				Expect(*other.Profiles[0].Deploy.StatusCheck).To(BeTrue())
+			})
+		})
+
+		When("project services publish ports", func() {
+
+			envs := []string{"dev"}
+			project := &tako.ComposeProject{
+				Project: &composego.Project{
+					Services: composego.Services(
+						[]composego.ServiceConfig{
+							{
+								Name:  "web",
+								Ports: []composego.ServicePortConfig{{Target: 80, Published: 8080}},
+							},
+							{
+								Name:  "worker",
+								Ports: []composego.ServicePortConfig{{Target: 9000}},
+							},
+							{
+								Name:  "Admin_UI",
+								Ports: []composego.ServicePortConfig{{Target: 80, Published: 8081}},
+							},
+						},
+					),
+				},
+			}
+			manifest := tako.BaseSkaffoldManifest()
+			manifest.SetProfiles(envs, tako.WithPortForwards(project))
+
+			It("forwards published service ports in each environment profile, using K8s service names", func() {
+				Expect(manifest.Profiles[0].PortForward).To(Equal([]*latest.PortForwardResource{
+					{
+						Type:      "service",
+						Name:      "web",
+						Port:      util.FromInt(8080),
+						LocalPort: 8080,
+					},
+					{
+						Type:      "service",
+						Name:      "admin-ui",
+						Port:      util.FromInt(8081),
+						LocalPort: 8081,
+					},
+				}))
+			})
+		})
+
 		When("there are no environments", func() {
 
 			envs := []string{}
@@ -168,6 +304,85 @@ var _ = Describe("Skaffold", func() {
 			})
 		})
 
+		When("manifests are rendered as helm charts", func() {
+
+			envs := []string{"dev", "prod"}
+			manifest := tako.BaseSkaffoldManifest()
+			manifest.SetProfilesForFormat(envs, tako.HelmManifestsFormat)
+
+			It("deploys a helm release per environment pointing at the environment chart", func() {
+				for i, p := range manifest.Profiles {
+					chartPath := filepath.Join(kubernetes.MultiFileSubDir, envs[i])
+
+					Expect(p.Deploy.KubectlDeploy).To(BeNil())
+					Expect(p.Deploy.LegacyHelmDeploy).ToNot(BeNil())
+					Expect(p.Deploy.LegacyHelmDeploy.Releases).To(Equal([]latest.HelmRelease{
+						{
+							Name:        envs[i],
+							ChartPath:   chartPath,
+							ValuesFiles: []string{filepath.Join(chartPath, "values.yaml")},
+						},
+					}))
+					Expect(p.Patches).To(Equal([]latest.JSONPatch{
+						{
+							Op:   "remove",
+							Path: "/manifests/rawYaml",
+						},
+					}))
+				}
+			})
+		})
+
+		When("manifests are rendered in kustomize format", func() {
+
+			envs := []string{"dev", "prod"}
+			manifest := tako.BaseSkaffoldManifest()
+			manifest.SetProfilesForFormat(envs, tako.KustomizeManifestsFormat)
+
+			It("renders environment manifests with kustomize pointing at the environment directory", func() {
+				for i, p := range manifest.Profiles {
+					Expect(p.Render.Generate.Kustomize).To(Equal(&latest.Kustomize{
+						Paths: []string{filepath.Join(kubernetes.MultiFileSubDir, envs[i])},
+					}))
+					Expect(p.Patches).To(Equal([]latest.JSONPatch{
+						{
+							Op:   "remove",
+							Path: "/manifests/rawYaml",
+						},
+					}))
+				}
+			})
+
+			It("deploys rendered manifests with kubectl", func() {
+				for _, p := range manifest.Profiles {
+					Expect(p.Deploy.KubectlDeploy).ToNot(BeNil())
+				}
+			})
+		})
+
+		When("manifests format is set as an option", func() {
+
+			manifest := tako.BaseSkaffoldManifest()
+			manifest.SetProfiles([]string{"dev"}, tako.WithManifestsFormat(tako.HelmManifestsFormat))
+
+			It("generates profiles for that format", func() {
+				Expect(manifest.Profiles).To(HaveLen(1))
+				Expect(manifest.Profiles[0].Deploy.LegacyHelmDeploy).ToNot(BeNil())
+				Expect(manifest.Profiles[0].Deploy.KubectlDeploy).To(BeNil())
+			})
+		})
+
+	})
+
+	Describe("ParseManifestsFormat", func() {
+		It("returns supported manifests formats", func() {
+			Expect(tako.ParseManifestsFormat("kustomize")).To(Equal(tako.KustomizeManifestsFormat))
+		})
+
+		It("returns an error for unsupported manifests formats", func() {
+			_, err := tako.ParseManifestsFormat("jsonnet")
+			Expect(err).To(MatchError(ContainSubstring(`unsupported manifests format "jsonnet"`)))
+		})
 	})
 
 	Describe("SetAdditionalProfiles", func() {
@@ -271,6 +486,46 @@ var _ = Describe("Skaffold", func() {
 
 		})
 
+		Context("for helm skaffold profile matching rendered environment", func() {
+			outputPath := "testdata" // point at any existing directory for test!
+
+			envToOutputPath := map[string]string{
+				envName: outputPath,
+			}
+
+			BeforeEach(func() {
+				manifest = tako.BaseSkaffoldManifest()
+				manifest.SetProfilesForFormat([]string{envName}, tako.HelmManifestsFormat)
+			})
+
+			It("updates the matching profile helm release chart path and values file", func() {
+				Expect(manifest.UpdateProfiles(envToOutputPath)).To(BeTrue())
+
+				release := manifest.Profiles[0].Deploy.LegacyHelmDeploy.Releases[0]
+				Expect(release.Name).To(Equal(envName))
+				Expect(release.ChartPath).To(Equal(outputPath))
+				Expect(release.ValuesFiles).To(Equal([]string{filepath.Join(outputPath, "values.yaml")}))
+			})
+		})
+
+		Context("for kustomize skaffold profile matching rendered environment", func() {
+			outputPath := "testdata" // point at any existing directory for test!
+
+			envToOutputPath := map[string]string{
+				envName: outputPath,
+			}
+
+			BeforeEach(func() {
+				manifest = tako.BaseSkaffoldManifest()
+				manifest.SetProfilesForFormat([]string{envName}, tako.KustomizeManifestsFormat)
+			})
+
+			It("updates the matching profile kustomization path to the rendered environment directory", func() {
+				Expect(manifest.UpdateProfiles(envToOutputPath)).To(BeTrue())
+				Expect(manifest.Profiles[0].Render.Generate.Kustomize.Paths).To(Equal([]string{outputPath}))
+			})
+		})
+
 		Context("when skaffold profile names don't match rendered enviornment", func() {
 			envToOutputPath := map[string]string{
 				"anotherEnv": "a/new/manifests/path",
@@ -380,6 +635,41 @@ var _ = Describe("Skaffold", func() {
 		})
 	})
 
+	Describe("LoadSkaffoldManifest", func() {
+		var dir, skaffoldPath string
+
+		BeforeEach(func() {
+			// Note, example skaffold uses an older skaffold/v2beta6 schema
+			data, err := os.ReadFile("testdata/init-default/skaffold/skaffold.yaml")
+			Expect(err).NotTo(HaveOccurred())
+
+			dir, err = os.MkdirTemp("", "tako-skaffold")
+			Expect(err).NotTo(HaveOccurred())
+
+			skaffoldPath = filepath.Join(dir, "skaffold.yaml")
+			Expect(os.WriteFile(skaffoldPath, data, 0644)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			_ = os.RemoveAll(dir)
+		})
+
+		It("upgrades older schema version to the latest", func() {
+			skaffoldManifest, err := tako.LoadSkaffoldManifest(skaffoldPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(skaffoldManifest.APIVersion).To(Equal(latest.Version))
+			Expect(skaffoldManifest.Profiles[0].Render.RawK8s).To(Equal([]string{"k8s/dev/*"}))
+		})
+
+		It("persists upgraded schema in place when profiles are updated", func() {
+			Expect(tako.UpdateSkaffoldProfiles(skaffoldPath, map[string]string{"dev": "k8s/dev"})).To(Succeed())
+
+			data, err := os.ReadFile(skaffoldPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("apiVersion: " + latest.Version))
+		})
+	})
+
 	Describe("InjectProfiles", func() {
 		var (
 			skaffoldManifest          *tako.SkaffoldManifest
@@ -422,6 +712,86 @@ var _ = Describe("Skaffold", func() {
 			})
 		})
 
+		When("tag policy is specified", func() {
+			BeforeEach(func() {
+				skaffoldManifest, err = tako.InjectProfiles(existingSkaffoldPath, []string{"prod"}, includeAdditionalProfiles,
+					tako.WithTagPolicy(tako.Sha256TagPolicy))
+			})
+
+			It("applies it to the existing build config", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(skaffoldManifest.Build.TagPolicy.ShaTagger).NotTo(BeNil())
+				Expect(skaffoldManifest.Build.TagPolicy.GitTagger).To(BeNil())
+			})
+		})
+
+		When("tag policy isn't specified", func() {
+			BeforeEach(func() {
+				skaffoldManifest, err = tako.InjectProfiles(existingSkaffoldPath, []string{"prod"}, includeAdditionalProfiles)
+			})
+
+			It("keeps the existing build tag policy", func() {
+				existing, loadErr := tako.LoadSkaffoldManifest(existingSkaffoldPath)
+				Expect(loadErr).ToNot(HaveOccurred())
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(skaffoldManifest.Build.TagPolicy).To(Equal(existing.Build.TagPolicy))
+			})
+		})
+
+		When("project services publish ports", func() {
+			BeforeEach(func() {
+				project := &tako.ComposeProject{
+					Project: &composego.Project{
+						Services: composego.Services{
+							{
+								Name:  "web",
+								Ports: []composego.ServicePortConfig{{Target: 80, Published: 8080}},
+							},
+						},
+					},
+				}
+
+				skaffoldManifest, err = tako.InjectProfiles(existingSkaffoldPath, []string{"prod"}, includeAdditionalProfiles,
+					tako.WithPortForwards(project))
+			})
+
+			It("forwards published service ports in the injected profiles", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(skaffoldManifest.Profiles).To(HaveLen(2))
+				Expect(skaffoldManifest.Profiles[1].Name).To(Equal("prod-env"))
+				Expect(skaffoldManifest.Profiles[1].PortForward).To(Equal([]*latest.PortForwardResource{
+					{
+						Type:      "service",
+						Name:      "web",
+						Port:      util.FromInt(8080),
+						LocalPort: 8080,
+					},
+				}))
+			})
+		})
+
+	})
+
+	Describe("ValidateSyncRules", func() {
+		It("accepts valid glob patterns", func() {
+			Expect(tako.ValidateSyncRules(map[string]*latest.Sync{
+				"web": {Manual: []*latest.SyncRule{{Src: "static/**/*.css", Dest: "/app"}}},
+				"api": {Infer: []string{"**/*.go"}},
+			})).To(Succeed())
+		})
+
+		It("returns an error for malformed glob patterns", func() {
+			Expect(tako.ValidateSyncRules(map[string]*latest.Sync{
+				"web": {Infer: []string{"static/[*.css"}},
+			})).To(MatchError(ContainSubstring("web")))
+		})
+
+		It("returns an error for manual rules without destination", func() {
+			Expect(tako.ValidateSyncRules(map[string]*latest.Sync{
+				"web": {Manual: []*latest.SyncRule{{Src: "*.css"}}},
+			})).To(HaveOccurred())
+		})
 	})
 
 	Describe("SetBuildArtifacts", func() {
@@ -430,14 +800,16 @@ var _ = Describe("Skaffold", func() {
 			skaffoldManifest *tako.SkaffoldManifest
 			project          *tako.ComposeProject
 			analysis         *tako.Analysis
+			opts             []tako.SkaffoldManifestOption
 		)
 
 		BeforeEach(func() {
 			skaffoldManifest = &tako.SkaffoldManifest{}
+			opts = nil
 		})
 
 		JustBeforeEach(func() {
-			skaffoldManifest.SetBuildArtifacts(analysis, project)
+			skaffoldManifest.SetBuildArtifacts(analysis, project, opts...)
 		})
 
 		Context("with detected service Dockerfiles", func() {
@@ -460,6 +832,33 @@ var _ = Describe("Skaffold", func() {
 				})
 			})
 
+			Context("and Dockerfile context directory name isn't a valid image name", func() {
+				BeforeEach(func() {
+					analysis = &tako.Analysis{
+						Dockerfiles: []string{"src/MyService/Dockerfile"},
+					}
+					project = &tako.ComposeProject{}
+				})
+
+				It("leaves the artifact image name untouched by default", func() {
+					Expect(skaffoldManifest.Build.Artifacts).To(HaveLen(1))
+					Expect(skaffoldManifest.Build.Artifacts[0].ImageName).To(Equal("MyService"))
+				})
+
+				When("image names are normalized", func() {
+					BeforeEach(func() {
+						opts = []tako.SkaffoldManifestOption{tako.WithImageNameTransformer(kubernetes.NormalizeImageName)}
+					})
+
+					It("normalizes the artifact image name consistently with rendered manifests", func() {
+						Expect(skaffoldManifest.Build.Artifacts).To(HaveLen(1))
+						Expect(skaffoldManifest.Build.Artifacts[0].ImageName).To(Equal("myservice"))
+						Expect(skaffoldManifest.Build.Artifacts[0].ImageName).To(Equal(kubernetes.NormalizeImageName("MyService")))
+						Expect(skaffoldManifest.Build.Artifacts[0].Workspace).To(Equal("src/MyService"))
+					})
+				})
+			})
+
 			Context("and no remote registry image names detected matching service name", func() {
 				BeforeEach(func() {
 					analysis = &tako.Analysis{
@@ -586,6 +985,178 @@ var _ = Describe("Skaffold", func() {
 						})
 					})
 
+					Context("with builder image configured", func() {
+						builderImage := "paketobuildpacks/builder:full"
+
+						BeforeEach(func() {
+							analysis.Dockerfiles = []string{}
+						})
+
+						It("uses configured builder image for each buildpacks artifact", func() {
+							manifest := &tako.SkaffoldManifest{}
+							manifest.SetBuildArtifacts(analysis, project, tako.WithBuilderImage(builderImage))
+
+							Expect(manifest.Build.Artifacts).ToNot(BeEmpty())
+							for _, a := range manifest.Build.Artifacts {
+								Expect(a.ArtifactType.BuildpackArtifact).ToNot(BeNil())
+								Expect(a.ArtifactType.BuildpackArtifact.Builder).To(Equal(builderImage))
+							}
+						})
+
+						It("preserves previously configured builder image when not specified", func() {
+							manifest := &tako.SkaffoldManifest{}
+							manifest.SetBuildArtifacts(analysis, project, tako.WithBuilderImage(builderImage))
+							manifest.SetBuildArtifacts(analysis, project)
+
+							Expect(manifest.Build.Artifacts[0].ArtifactType.BuildpackArtifact.Builder).To(Equal(builderImage))
+						})
+					})
+
+					Context("with sync rules configured", func() {
+						sync := &latest.Sync{
+							Manual: []*latest.SyncRule{{Src: "src/**/*.js", Dest: "."}},
+						}
+
+						It("sets sync rules on the artifact built from the service context", func() {
+							manifest := &tako.SkaffoldManifest{}
+							manifest.SetBuildArtifacts(analysis, project, tako.WithSyncRules(map[string]*latest.Sync{"svc1": sync}))
+
+							Expect(manifest.Build.Artifacts).To(HaveLen(1))
+							Expect(manifest.Build.Artifacts[0].Workspace).To(Equal(context))
+							Expect(manifest.Build.Artifacts[0].Sync).To(Equal(sync))
+						})
+
+						It("skips sync rules with invalid glob patterns", func() {
+							invalid := &latest.Sync{Infer: []string{"src/[*.js"}}
+
+							manifest := &tako.SkaffoldManifest{}
+							manifest.SetBuildArtifacts(analysis, project, tako.WithSyncRules(map[string]*latest.Sync{"svc1": invalid}))
+
+							Expect(manifest.Build.Artifacts[0].Sync).To(BeNil())
+						})
+
+						It("doesn't apply sync rules of another service sharing the build context", func() {
+							other := &latest.Sync{Infer: []string{"**/*.py"}}
+							project.Project.Services = append(composego.Services{
+								{
+									Name:  "svc2",
+									Image: "quay.io/org/other:latest",
+									Build: &composego.BuildConfig{
+										Context: context,
+									},
+								},
+							}, project.Project.Services...)
+
+							manifest := &tako.SkaffoldManifest{}
+							manifest.SetBuildArtifacts(analysis, project, tako.WithSyncRules(map[string]*latest.Sync{"svc2": other}))
+
+							Expect(manifest.Build.Artifacts).To(HaveLen(1))
+							Expect(manifest.Build.Artifacts[0].ImageName).To(Equal(image))
+							Expect(manifest.Build.Artifacts[0].Sync).To(BeNil())
+						})
+
+						It("sets sync rules configured via the service extension", func() {
+							project.Project.Services[0].Extensions = map[string]interface{}{
+								tako.SkaffoldSyncExtensionKey: map[string]interface{}{
+									"manual": []interface{}{
+										map[string]interface{}{"src": "src/**/*.js", "dest": "."},
+									},
+								},
+							}
+
+							manifest := &tako.SkaffoldManifest{}
+							manifest.SetBuildArtifacts(analysis, project)
+
+							Expect(manifest.Build.Artifacts[0].Sync).To(Equal(sync))
+						})
+
+						It("prefers explicitly configured sync rules over the service extension", func() {
+							project.Project.Services[0].Extensions = map[string]interface{}{
+								tako.SkaffoldSyncExtensionKey: map[string]interface{}{
+									"infer": []interface{}{"**/*.go"},
+								},
+							}
+
+							manifest := &tako.SkaffoldManifest{}
+							manifest.SetBuildArtifacts(analysis, project, tako.WithSyncRules(map[string]*latest.Sync{"svc1": sync}))
+
+							Expect(manifest.Build.Artifacts[0].Sync).To(Equal(sync))
+						})
+					})
+
+					Context("with image registry configured", func() {
+						BeforeEach(func() {
+							analysis.Dockerfiles = []string{"src/myservice/Dockerfile"}
+						})
+
+						It("prefixes artifact image names not referencing a registry and keeps their contexts", func() {
+							manifest := &tako.SkaffoldManifest{}
+							manifest.SetBuildArtifacts(analysis, project, tako.WithImageRegistry("registry.example.com/team"))
+
+							Expect(manifest.Build.Artifacts).To(ContainElements(
+								&latest.Artifact{
+									ImageName: "registry.example.com/team/myservice",
+									Workspace: "src/myservice",
+								},
+								&latest.Artifact{
+									ImageName: image,
+									Workspace: context,
+								},
+							))
+						})
+					})
+
+					Context("with kaniko cluster build configured", func() {
+						BeforeEach(func() {
+							analysis.Dockerfiles = []string{"src/myservice/Dockerfile"}
+							project.Services[0].Build.Dockerfile = "build/Dockerfile"
+						})
+
+						It("replaces local build with a cluster build in the configured namespace", func() {
+							manifest := tako.BaseSkaffoldManifest()
+							manifest.SetBuildArtifacts(analysis, project, tako.WithKanikoClusterBuild("ci"))
+
+							Expect(manifest.Build.LocalBuild).To(BeNil())
+							Expect(manifest.Build.Cluster).ToNot(BeNil())
+							Expect(manifest.Build.Cluster.Namespace).To(Equal("ci"))
+						})
+
+						It("uses kaniko build strategy with the discovered Dockerfile for each artifact", func() {
+							manifest := tako.BaseSkaffoldManifest()
+							manifest.SetBuildArtifacts(analysis, project, tako.WithKanikoClusterBuild("ci"))
+
+							Expect(manifest.Build.Artifacts).To(ContainElements(
+								&latest.Artifact{
+									ImageName: "myservice",
+									Workspace: "src/myservice",
+									ArtifactType: latest.ArtifactType{
+										KanikoArtifact: &latest.KanikoArtifact{
+											DockerfilePath: "Dockerfile",
+										},
+									},
+								},
+								&latest.Artifact{
+									ImageName: image,
+									Workspace: context,
+									ArtifactType: latest.ArtifactType{
+										KanikoArtifact: &latest.KanikoArtifact{
+											DockerfilePath: "build/Dockerfile",
+										},
+									},
+								},
+							))
+						})
+
+						It("preserves previously configured cluster build when not specified", func() {
+							manifest := tako.BaseSkaffoldManifest()
+							manifest.SetBuildArtifacts(analysis, project, tako.WithKanikoClusterBuild("ci"))
+							manifest.SetBuildArtifacts(analysis, project)
+
+							Expect(manifest.Build.Cluster).ToNot(BeNil())
+							Expect(manifest.Build.Artifacts[0].ArtifactType.KanikoArtifact).ToNot(BeNil())
+						})
+					})
+
 					Context("with nil analysis", func() {
 						BeforeEach(func() {
 							analysis = nil
@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 
 	"github.com/appvia/tako/pkg/tako/config"
+	"github.com/appvia/tako/pkg/tako/converter/kubernetes"
 	"github.com/appvia/tako/pkg/tako/log"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
@@ -45,6 +46,15 @@ func (s *Sources) CalculateBaseOverride(opts ...BaseOverrideOpts) error {
 	}
 
 	for _, svc := range ready.Services {
+		// load x-k8s referenced with `$ref`, so that the written overrides don't reset referenced values to defaults
+		extensions, err := kubernetes.ResolveK8sExtensionRef(svc.Extensions, s.getWorkingDir())
+		if err != nil {
+			return errors.Wrapf(err, "when parsing service %s extensions", svc.Name)
+		}
+
+		// apply project level x-k8s defaults, so that the written overrides don't reset them to global defaults
+		svc.Extensions = kubernetes.WithProjectK8sDefaults(extensions, ready.Extensions)
+
 		target := ServiceConfig{
 			Name:       svc.Name,
 			Extensions: svc.Extensions,
@@ -36,6 +36,10 @@ type runConfig struct {
 	ManifestsAsSingleFile bool
 	// AdditionalManifests is a list of additional manifests that should be added to the generated manifests set
 	AdditionalManifests []string
+	// LegacySecretKeys indicates whether single file secrets should be stored and mounted under the secret name
+	LegacySecretKeys bool
+	// ImageTagsFile is a YAML file with per environment service image tags applied when rendering
+	ImageTagsFile string
 	// OutputDir is a directory where to store the generated manifests
 	OutputDir string
 	// K8sNamespace is a target Kubernetes namespace
@@ -44,6 +48,20 @@ type runConfig struct {
 	Kubecontext string
 	// Skaffold is a flag indicating whether to generate skaffold.yaml
 	Skaffold bool
+	// SkaffoldKubeContexts is a flag indicating whether skaffold environment profiles should be bound to `<env>-context` kube-contexts
+	SkaffoldKubeContexts bool
+	// SkaffoldManifestsFormat is a format of K8s manifests deployed by Skaffold environment profiles
+	SkaffoldManifestsFormat string
+	// SkaffoldTagPolicy is a policy used by Skaffold to tag built images
+	SkaffoldTagPolicy string
+	// SkaffoldStatusCheckDisabled is a flag indicating whether skaffold environment profiles shouldn't wait for deployed resources to stabilize
+	SkaffoldStatusCheckDisabled bool
+	// SkaffoldStatusCheckDeadlineSeconds is a time skaffold environment profiles wait for deployed resources to stabilize
+	SkaffoldStatusCheckDeadlineSeconds int
+	// ImageRegistry is a registry prepended to built images that don't reference a registry
+	ImageRegistry string
+	// NormalizeImageNames indicates whether image names should be normalized with kubernetes.NormalizeImageName
+	NormalizeImageNames bool
 	// SkaffoldTail is a flag indicating whether to tail skaffold logs
 	SkaffoldTail bool
 	// SkaffoldManualTrigger is a flag indicating whether trigger changes manually when skaffold dev loop is running
@@ -127,11 +145,13 @@ type PatchRunner struct {
 
 // Manifest contains the tracked project's docker-compose sources and deployment environments
 type Manifest struct {
-	Id           string       `yaml:"id,omitempty" json:"id,omitempty"`
-	Sources      *Sources     `yaml:"compose,omitempty" json:"compose,omitempty"`
-	Environments Environments `yaml:"environments,omitempty" json:"environments,omitempty"`
-	Skaffold     string       `yaml:"skaffold,omitempty" json:"skaffold,omitempty"`
-	UI           kmd.UI       `yaml:"-" json:"-"`
+	Id                  string       `yaml:"id,omitempty" json:"id,omitempty"`
+	Sources             *Sources     `yaml:"compose,omitempty" json:"compose,omitempty"`
+	Environments        Environments `yaml:"environments,omitempty" json:"environments,omitempty"`
+	Skaffold            string       `yaml:"skaffold,omitempty" json:"skaffold,omitempty"`
+	ImageRegistry       string       `yaml:"imageRegistry,omitempty" json:"imageRegistry,omitempty"`
+	NormalizeImageNames bool         `yaml:"normalizeImageNames,omitempty" json:"normalizeImageNames,omitempty"`
+	UI                  kmd.UI       `yaml:"-" json:"-"`
 }
 
 // Sources tracks a project's docker-compose sources